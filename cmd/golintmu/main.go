@@ -1,10 +1,92 @@
+// Command golintmu reports inconsistent mutex usage.
+//
+// By default it is a standard single-analyzer driver (text diagnostics,
+// -fix, -json, go vet -vettool). With -format=json it prints the structured
+// findings described by analyzer.Finding instead, and with -format=sarif a
+// SARIF 2.1.0 log for code-scanning tools. -write-baseline records the
+// current findings in the file named by -baseline, whose findings are then
+// no longer reported. -whole-program analyzes the packages as a single
+// program (see analyzer.RunWholeProgram), so that the accesses of importers
+// feed the guard inference of the types they use.
 package main
 
 import (
+	"flag"
+	"os"
+	"strings"
+
 	"github.com/akerouanton/golintmu/pkg/analyzer"
 	"golang.org/x/tools/go/analysis/singlechecker"
 )
 
+const (
+	formatUsage        = "output format: text, json (structured findings, see analyzer.Finding) or sarif (SARIF 2.1.0)"
+	writeBaselineUsage = "record the current findings in the -baseline file instead of reporting them"
+	wholeProgramUsage  = "analyze the packages as a single program, without their tests: guards are inferred from the accesses of all packages"
+)
+
 func main() {
+	if outputFormat(os.Args[1:]) != "text" || hasFlag(os.Args[1:], "write-baseline") || hasFlag(os.Args[1:], "whole-program") {
+		os.Exit(runStructured())
+	}
+	flag.String("format", "text", formatUsage)
+	flag.Bool("write-baseline", false, writeBaselineUsage)
+	flag.Bool("whole-program", false, wholeProgramUsage)
 	singlechecker.Main(analyzer.Analyzer)
 }
+
+// outputFormat returns the value of the -format flag in args, before flags
+// are parsed: text output is handled by singlechecker, which owns parsing.
+func outputFormat(args []string) string {
+	format := "text"
+	scanFlags(args, func(name, value string) {
+		if name == "format" {
+			format = value
+		}
+	})
+	return format
+}
+
+// hasFlag reports whether the boolean flag name is set in args, before flags
+// are parsed.
+func hasFlag(args []string, name string) bool {
+	set := false
+	scanFlags(args, func(n, value string) {
+		if n == name {
+			set = value == "" || value == "true" || value == "1"
+		}
+	})
+	return set
+}
+
+// scanFlags calls fn for each flag of args until the first non-flag argument.
+// The value of -format and of non-boolean analyzer flags may be the next
+// argument; other flags without "=" have an empty value.
+func scanFlags(args []string, fn func(name, value string)) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || !strings.HasPrefix(arg, "-") {
+			return
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !hasValue && takesValue(name) && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		fn(name, value)
+	}
+}
+
+// takesValue reports whether the flag name is -format or a non-boolean
+// analyzer flag.
+func takesValue(name string) bool {
+	if name == "format" {
+		return true
+	}
+	f := analyzer.Analyzer.Flags.Lookup(name)
+	if f == nil {
+		return false
+	}
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return !ok || !b.IsBoolFlag()
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/akerouanton/golintmu/pkg/analyzer"
+)
+
+// SARIF 2.1.0 document, restricted to the properties golintmu emits.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolURI      = "https://github.com/akerouanton/golintmu"
+	docBaseURI   = toolURI + "/blob/main/"
+
+	// srcRoot is the uriBaseId of relative artifact locations: the
+	// directory golintmu was run from.
+	srcRoot = "%SRCROOT%"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	FullDescription      sarifMessage       `json:"fullDescription"`
+	HelpURI              string             `json:"helpUri"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	RuleIndex        int             `json:"ruleIndex"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+	CodeFlows        []sarifCodeFlow `json:"codeFlows,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               *int                  `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifCodeFlow struct {
+	ThreadFlows []sarifThreadFlow `json:"threadFlows"`
+}
+
+type sarifThreadFlow struct {
+	Locations []sarifThreadFlowLocation `json:"locations"`
+}
+
+type sarifThreadFlowLocation struct {
+	Location sarifLocation `json:"location"`
+}
+
+// sarifRules returns one rule per catalog entry and one for directive
+// diagnostics, and the index of each rule by catalog ID. The default level of
+// a rule is the check's severity under the -severity flag; severities set by
+// a configuration file, which may differ between packages, or escalated for
+// a single finding are only result levels.
+func sarifRules() ([]sarifRule, map[string]int) {
+	checks := append(append([]analyzer.Check(nil), analyzer.Catalog...), analyzer.DirectiveCheck)
+	rules := make([]sarifRule, 0, len(checks))
+	index := make(map[string]int, len(checks))
+	for i, c := range checks {
+		rules = append(rules, sarifRule{
+			ID:                   c.ID,
+			Name:                 ruleName(c.Name),
+			ShortDescription:     sarifMessage{Text: c.Name},
+			FullDescription:      sarifMessage{Text: c.Description},
+			HelpURI:              docBaseURI + c.Doc,
+			DefaultConfiguration: sarifConfiguration{Level: analyzer.Severity(c.ID)},
+		})
+		index[c.ID] = i
+	}
+	return rules, index
+}
+
+// ruleName turns a catalog title into a SARIF rule name:
+// "Lock leak / missing unlock" becomes "LockLeakMissingUnlock".
+func ruleName(title string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(title, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	}) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// sarifPhysical converts a finding position. Relative paths are resolved
+// against %SRCROOT%; absolute paths become file URIs.
+func sarifPhysical(p analyzer.Position) sarifPhysicalLocation {
+	loc := sarifArtifactLocation{URI: filepath.ToSlash(p.File), URIBaseID: srcRoot}
+	if filepath.IsAbs(p.File) {
+		loc = sarifArtifactLocation{URI: "file://" + filepath.ToSlash(p.File)}
+	}
+	return sarifPhysicalLocation{
+		ArtifactLocation: loc,
+		Region:           sarifRegion{StartLine: p.Line, StartColumn: p.Column},
+	}
+}
+
+// sarifResultFor converts a finding. Related positions (lock-order cycle
+// edges, acquire sites) become relatedLocations; each provenance chain
+// becomes a code flow from the diagnostic to the root cause.
+func sarifResultFor(f analyzer.Finding, ruleIndex int) sarifResult {
+	res := sarifResult{
+		RuleID:    f.Check,
+		RuleIndex: ruleIndex,
+		Level:     f.Severity,
+		Message:   sarifMessage{Text: f.Message},
+		Locations: []sarifLocation{{PhysicalLocation: sarifPhysical(f.Pos)}},
+	}
+	for i, r := range f.Related {
+		id := i + 1
+		res.RelatedLocations = append(res.RelatedLocations, sarifLocation{
+			ID:               &id,
+			PhysicalLocation: sarifPhysical(r.Pos),
+			Message:          &sarifMessage{Text: r.Message},
+		})
+	}
+	for _, chain := range f.Provenance {
+		flow := sarifThreadFlow{Locations: []sarifThreadFlowLocation{{
+			Location: sarifLocation{
+				PhysicalLocation: sarifPhysical(f.Pos),
+				Message:          &sarifMessage{Text: f.Message},
+			},
+		}}}
+		for _, step := range chain {
+			flow.Locations = append(flow.Locations, sarifThreadFlowLocation{
+				Location: sarifLocation{
+					PhysicalLocation: sarifPhysical(step.Pos),
+					Message:          &sarifMessage{Text: step.Description()},
+				},
+			})
+		}
+		res.CodeFlows = append(res.CodeFlows, sarifCodeFlow{ThreadFlows: []sarifThreadFlow{flow}})
+	}
+	return res
+}
+
+// writeSARIF prints findings as a SARIF 2.1.0 log with a single run.
+func writeSARIF(w io.Writer, findings []analyzer.Finding) error {
+	rules, index := sarifRules()
+	results := make([]sarifResult, 0, len(findings))
+	for _, f := range findings {
+		i, ok := index[f.Check]
+		if !ok {
+			continue
+		}
+		results = append(results, sarifResultFor(f, i))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "golintmu",
+				InformationURI: toolURI,
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	})
+}
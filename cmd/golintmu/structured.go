@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/akerouanton/golintmu/pkg/analyzer"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+// jsonSchemaVersion is bumped on incompatible changes to the -format=json
+// document. Adding fields to analyzer.Finding is not one.
+const jsonSchemaVersion = 1
+
+// jsonReport is the document printed by -format=json.
+type jsonReport struct {
+	Version  int                `json:"version"`
+	Findings []analyzer.Finding `json:"findings"`
+}
+
+// writers maps the output formats to their writers. Text output goes through
+// runStructured only with -whole-program.
+var writers = map[string]func(io.Writer, []analyzer.Finding) error{
+	"text":  writeText,
+	"json":  writeJSON,
+	"sarif": writeSARIF,
+}
+
+// runStructured parses the command line, analyzes the packages and prints
+// their findings in the requested format, or writes them to the baseline file
+// with -write-baseline. Like `go vet -json`, it exits 0 when findings were
+// reported, and 1 on errors. Text output is printed to stderr and exits 3
+// when findings were reported, like the standard driver.
+func runStructured() int {
+	format := flag.String("format", "text", formatUsage)
+	tests := flag.Bool("test", true, "indicates whether test files should be analyzed, too")
+	flag.Bool("write-baseline", false, writeBaselineUsage)
+	wholeProgram := flag.Bool("whole-program", false, wholeProgramUsage)
+	analyzer.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		flag.Var(f.Value, f.Name, f.Usage)
+	})
+	flag.Parse()
+
+	if hasFlag(os.Args[1:], "write-baseline") {
+		return writeBaseline(flag.Args(), *tests, *wholeProgram)
+	}
+
+	write, ok := writers[*format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "golintmu: unknown output format %q (want text, json or sarif)\n", *format)
+		return 1
+	}
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "golintmu: no packages to analyze")
+		return 1
+	}
+	// Structured output always carries provenance chains.
+	if *format != "text" {
+		if err := analyzer.Analyzer.Flags.Set("verbose", "true"); err != nil {
+			fmt.Fprintf(os.Stderr, "golintmu: %v\n", err)
+			return 1
+		}
+	}
+
+	findings, err := analyze(flag.Args(), *tests, *wholeProgram)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "golintmu: %v\n", err)
+		return 1
+	}
+	if wd, err := os.Getwd(); err == nil {
+		relativize(findings, wd)
+	}
+	out := os.Stdout
+	if *format == "text" {
+		out = os.Stderr
+	}
+	if err := write(out, findings); err != nil {
+		fmt.Fprintf(os.Stderr, "golintmu: %v\n", err)
+		return 1
+	}
+	if *format == "text" && len(findings) > 0 {
+		return 3
+	}
+	return 0
+}
+
+// writeBaseline records the findings of the packages matching patterns in
+// the file named by -baseline, ignoring its current content.
+func writeBaseline(patterns []string, tests, wholeProgram bool) int {
+	path := analyzer.Analyzer.Flags.Lookup("baseline").Value.String()
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "golintmu: -write-baseline requires -baseline=FILE")
+		return 1
+	}
+	if len(patterns) == 0 {
+		fmt.Fprintln(os.Stderr, "golintmu: no packages to analyze")
+		return 1
+	}
+	if err := analyzer.Analyzer.Flags.Set("baseline", ""); err != nil {
+		fmt.Fprintf(os.Stderr, "golintmu: %v\n", err)
+		return 1
+	}
+
+	findings, err := analyze(patterns, tests, wholeProgram)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "golintmu: %v\n", err)
+		return 1
+	}
+	var buf bytes.Buffer
+	if err := analyzer.NewBaseline(findings).Write(&buf); err != nil {
+		fmt.Fprintf(os.Stderr, "golintmu: %v\n", err)
+		return 1
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "golintmu: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "golintmu: recorded %d findings in %s\n", len(findings), path)
+	return 0
+}
+
+// analyze loads the packages matching patterns and returns the findings of
+// the root packages, deduplicated (test variants repeat the package's files)
+// and sorted by position. With wholeProgram, the packages are analyzed as a
+// single program, without their tests.
+func analyze(patterns []string, tests, wholeProgram bool) ([]analyzer.Finding, error) {
+	cfg := &packages.Config{Mode: packages.LoadAllSyntax | packages.NeedModule, Tests: tests && !wholeProgram}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	if n := packages.PrintErrors(pkgs); n > 0 {
+		return nil, fmt.Errorf("%d errors while loading packages", n)
+	}
+
+	var results []analyzer.Finding
+	if wholeProgram {
+		results, err = analyzer.RunWholeProgram(pkgs)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		graph, err := checker.Analyze([]*analysis.Analyzer{analyzer.Analyzer}, pkgs, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, act := range graph.Roots {
+			if act.Err != nil {
+				return nil, fmt.Errorf("%s: %v", act.Package.PkgPath, act.Err)
+			}
+			results = append(results, act.Result.([]analyzer.Finding)...)
+		}
+	}
+
+	type findingKey struct {
+		check, message string
+		pos            analyzer.Position
+	}
+	seen := make(map[findingKey]bool)
+	findings := []analyzer.Finding{}
+	for _, f := range results {
+		key := findingKey{check: f.Check, message: f.Message, pos: f.Pos}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		findings = append(findings, f)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i].Pos, findings[j].Pos
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return findings[i].Message < findings[j].Message
+	})
+	return findings, nil
+}
+
+// relativize rewrites file names below dir as slash-separated paths relative
+// to dir, so that reports are stable across checkouts.
+func relativize(findings []analyzer.Finding, dir string) {
+	rel := func(p *analyzer.Position) {
+		r, err := filepath.Rel(dir, p.File)
+		if err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			return
+		}
+		p.File = filepath.ToSlash(r)
+	}
+	for i := range findings {
+		f := &findings[i]
+		rel(&f.Pos)
+		for j := range f.Related {
+			rel(&f.Related[j].Pos)
+		}
+		for _, chain := range f.Provenance {
+			for j := range chain {
+				rel(&chain[j].Pos)
+			}
+		}
+	}
+}
+
+// writeText prints findings like the standard driver, one "file:line:col:
+// message" line each, followed by the provenance chains with -verbose.
+func writeText(w io.Writer, findings []analyzer.Finding) error {
+	verbose := analyzer.Analyzer.Flags.Lookup("verbose").Value.String() == "true"
+	for _, f := range findings {
+		if _, err := fmt.Fprintf(w, "%s:%d:%d: %s\n", f.Pos.File, f.Pos.Line, f.Pos.Column, f.Message); err != nil {
+			return err
+		}
+		if !verbose {
+			continue
+		}
+		for i, chain := range f.Provenance {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			for _, step := range chain {
+				fmt.Fprintf(w, "\t%s at %s:%d:%d\n", step.Description(),
+					filepath.Base(step.Pos.File), step.Pos.Line, step.Pos.Column)
+			}
+		}
+	}
+	return nil
+}
+
+// writeJSON prints findings as an indented jsonReport.
+func writeJSON(w io.Writer, findings []analyzer.Finding) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jsonReport{Version: jsonSchemaVersion, Findings: findings})
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akerouanton/golintmu/pkg/analyzer"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// analyzeGolden runs the analyzer on a package of the analyzer's testdata
+// with provenance enabled, as runStructured does, and returns its findings
+// with paths relative to the module root.
+func analyzeGolden(t *testing.T, pkg string, wholeProgram bool) []analyzer.Finding {
+	t.Helper()
+	if err := analyzer.Analyzer.Flags.Set("verbose", "true"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := analyzer.Analyzer.Flags.Set("verbose", "false"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	findings, err := analyze([]string{"../../pkg/analyzer/testdata/src/" + pkg}, false, wholeProgram)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	relativize(findings, root)
+	return findings
+}
+
+// checkGolden compares got with testdata/name, rewriting it with -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	golden := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s mismatch (run go test -update to regenerate):\n%s", golden, got)
+	}
+}
+
+func TestJSONOutput(t *testing.T) {
+	findings := analyzeGolden(t, "structured_output", false)
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, findings); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "structured_output.json", buf.Bytes())
+}
+
+func TestSARIFOutput(t *testing.T) {
+	for _, pkg := range []string{"structured_output", "lock_ordering", "cross_goroutine_unlock", "interprocedural_verbose"} {
+		t.Run(pkg, func(t *testing.T) {
+			findings := analyzeGolden(t, pkg, false)
+			var buf bytes.Buffer
+			if err := writeSARIF(&buf, findings); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, pkg+".sarif", buf.Bytes())
+		})
+	}
+}
+
+// TestWholeProgramOutput checks that the whole-program mode reports the
+// findings of the go/analysis driver on a single package, and the text output.
+func TestWholeProgramOutput(t *testing.T) {
+	for _, pkg := range []string{"structured_output", "lock_ordering", "cross_goroutine_unlock", "interprocedural_verbose"} {
+		t.Run(pkg, func(t *testing.T) {
+			var want, got bytes.Buffer
+			if err := writeJSON(&want, analyzeGolden(t, pkg, false)); err != nil {
+				t.Fatal(err)
+			}
+			findings := analyzeGolden(t, pkg, true)
+			if err := writeJSON(&got, findings); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != want.String() {
+				t.Errorf("whole-program findings differ:\n%s\nwant:\n%s", got.String(), want.String())
+			}
+		})
+	}
+
+	findings := analyzeGolden(t, "interprocedural_verbose", true)
+	var buf bytes.Buffer
+	if err := writeText(&buf, findings); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "interprocedural_verbose.txt", buf.Bytes())
+}
+
+func TestSARIFLevels(t *testing.T) {
+	// Findings of one check with severities configured per package.
+	findings := []analyzer.Finding{
+		{Check: "C1", Severity: analyzer.SeverityNote, Message: "a"},
+		{Check: "C1", Severity: analyzer.SeverityWarning, Message: "b"},
+	}
+	var buf bytes.Buffer
+	if err := writeSARIF(&buf, findings); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	run := log.Runs[0]
+	for _, r := range run.Tool.Driver.Rules {
+		if r.ID == "C1" && r.DefaultConfiguration.Level != analyzer.SeverityError {
+			t.Errorf("C1 rule has default level %q, want the catalog severity %q", r.DefaultConfiguration.Level, analyzer.SeverityError)
+		}
+	}
+	if len(run.Results) != 2 || run.Results[0].Level != analyzer.SeverityNote || run.Results[1].Level != analyzer.SeverityWarning {
+		t.Errorf("unexpected results: %+v", run.Results)
+	}
+}
+
+func TestRuleName(t *testing.T) {
+	tests := map[string]string{
+		"Inconsistent field locking":      "InconsistentFieldLocking",
+		"Lock leak / missing unlock":      "LockLeakMissingUnlock",
+		"Deferred Lock instead of Unlock": "DeferredLockInsteadOfUnlock",
+	}
+	for title, want := range tests {
+		if got := ruleName(title); got != want {
+			t.Errorf("ruleName(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestWriteBaseline(t *testing.T) {
+	findings := analyzeGolden(t, "structured_output", false)
+	if len(findings) == 0 {
+		t.Fatal("no findings")
+	}
+	var buf bytes.Buffer
+	if err := analyzer.NewBaseline(findings).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "golintmu.baseline")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b, err := analyzer.ReadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, e := range b.Findings {
+		total += e.Count
+	}
+	if total != len(findings) {
+		t.Errorf("baseline records %d findings, want %d", total, len(findings))
+	}
+
+	if err := analyzer.Analyzer.Flags.Set("baseline", path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := analyzer.Analyzer.Flags.Set("baseline", ""); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if got := analyzeGolden(t, "structured_output", false); len(got) != 0 {
+		t.Errorf("got %d findings with the baseline, want none: %+v", len(got), got)
+	}
+}
+
+func TestOutputFormat(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "text"},
+		{[]string{"./..."}, "text"},
+		{[]string{"-format=json", "./..."}, "json"},
+		{[]string{"--format", "json", "./..."}, "json"},
+		{[]string{"-verbose", "-format=json", "./..."}, "json"},
+		{[]string{"./...", "-format=json"}, "text"},
+		{[]string{"-baseline", "golintmu.baseline", "-format", "sarif", "./..."}, "sarif"},
+	}
+	for _, tt := range tests {
+		if got := outputFormat(tt.args); got != tt.want {
+			t.Errorf("outputFormat(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestHasFlag(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{nil, false},
+		{[]string{"-write-baseline", "./..."}, true},
+		{[]string{"-baseline", "golintmu.baseline", "-write-baseline", "./..."}, true},
+		{[]string{"-write-baseline=false", "./..."}, false},
+		{[]string{"./...", "-write-baseline"}, false},
+	}
+	for _, tt := range tests {
+		if got := hasFlag(tt.args, "write-baseline"); got != tt.want {
+			t.Errorf("hasFlag(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
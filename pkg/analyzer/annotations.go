@@ -1,8 +1,10 @@
 package analyzer
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
 	"strings"
 
 	"golang.org/x/tools/go/ssa"
@@ -10,18 +12,70 @@ import (
 
 // annotations holds parsed comment directives for the current package.
 type annotations struct {
-	concurrent map[*ssa.Function]bool // functions marked //mu:concurrent
-	ignored    map[*ssa.Function]bool // functions marked //mu:ignore
-	nolint     map[string]map[int]bool // filename → set of suppressed line numbers
+	concurrent   map[*ssa.Function]bool            // functions marked //mu:concurrent
+	ignored      map[*ssa.Function][]*suppression  // functions marked //mu:ignore
+	nolint       map[string]map[int][]*suppression // filename → suppressed line numbers
+	suppressions []*suppression                    // every valid //mu:ignore and //mu:nolint, in source order
+	contracts    map[*ssa.Function]*lockContract   // functions with //mu:requires, //mu:acquires or //mu:releases
+	fieldGuards  map[fieldKey]int                  // fields marked //mu:guardedby (guard field index) or //mu:unguarded (-1)
+}
+
+// suppression is a //mu:ignore or //mu:nolint directive.
+type suppression struct {
+	comment *ast.Comment
+	text    string          // directive without the reason, e.g. "mu:nolint:C5"
+	checks  map[string]bool // checks silenced; nil silences every check
+	used    bool            // the directive suppressed a diagnostic
+}
+
+// covers reports whether the suppression silences check.
+func (s *suppression) covers(check string) bool {
+	return s.checks == nil || s.checks[check]
+}
+
+// directive is a parsed //mu: comment, e.g. "//mu:nolint:C1,C5 // reason".
+type directive struct {
+	text   string   // directive without the reason, e.g. "mu:nolint:C1,C5"
+	name   string   // "nolint", "ignore", "concurrent", ...
+	checks []string // catalog IDs after the name; nil when absent
+	args   string   // text following the directive
+	reason string   // text following the directive, without a leading "//"
+}
+
+// parseDirective parses the text of a comment. ok is false for comments that
+// are not //mu: directives.
+func parseDirective(text string) (d directive, ok bool) {
+	text = strings.TrimSpace(strings.TrimPrefix(text, "//"))
+	token, rest, _ := strings.Cut(text, " ")
+	name, ok := strings.CutPrefix(token, "mu:")
+	if !ok {
+		return directive{}, false
+	}
+	name, list, hasList := strings.Cut(name, ":")
+	d.text = token
+	d.name = name
+	if hasList {
+		d.checks = []string{}
+		for _, id := range strings.Split(list, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				d.checks = append(d.checks, id)
+			}
+		}
+	}
+	d.args = strings.TrimSpace(rest)
+	d.reason = strings.TrimSpace(strings.TrimPrefix(d.args, "//"))
+	return d, true
 }
 
 // parseAnnotations scans all comment groups in the package's AST files and
 // populates ctx.annotations with directive information.
 func (ctx *passContext) parseAnnotations() {
 	ann := &annotations{
-		concurrent: make(map[*ssa.Function]bool),
-		ignored:    make(map[*ssa.Function]bool),
-		nolint:     make(map[string]map[int]bool),
+		concurrent:  make(map[*ssa.Function]bool),
+		ignored:     make(map[*ssa.Function][]*suppression),
+		nolint:      make(map[string]map[int][]*suppression),
+		contracts:   make(map[*ssa.Function]*lockContract),
+		fieldGuards: make(map[fieldKey]int),
 	}
 
 	fset := ctx.pass.Fset
@@ -34,30 +88,55 @@ func (ctx *passContext) parseAnnotations() {
 				funcDecls = append(funcDecls, fd)
 			}
 		}
+		fieldComments := ctx.structFieldComments(file)
 
 		for _, cg := range file.Comments {
 			for _, comment := range cg.List {
-				text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+				d, ok := parseDirective(comment.Text)
+				if !ok {
+					continue
+				}
 
-				switch {
-				case text == "mu:concurrent" || strings.HasPrefix(text, "mu:concurrent "):
+				switch d.name {
+				case "concurrent":
 					if fn := ctx.findFuncForComment(fset, funcDecls, comment.Pos()); fn != nil {
 						ann.concurrent[fn] = true
 					}
 
-				case text == "mu:ignore" || strings.HasPrefix(text, "mu:ignore "):
+				case "ignore":
+					sup, ok := ctx.parseSuppression(comment, d)
+					if !ok {
+						continue
+					}
+					ann.suppressions = append(ann.suppressions, sup)
 					if fn := ctx.findFuncForComment(fset, funcDecls, comment.Pos()); fn != nil {
-						ann.ignored[fn] = true
+						ann.ignored[fn] = append(ann.ignored[fn], sup)
 					}
 
-				case text == "mu:nolint" || strings.HasPrefix(text, "mu:nolint "):
+				case "nolint":
+					sup, ok := ctx.parseSuppression(comment, d)
+					if !ok {
+						continue
+					}
+					ann.suppressions = append(ann.suppressions, sup)
+					// A trailing comment suppresses its own line, a comment
+					// on its own line the next one.
 					pos := fset.Position(comment.Pos())
 					filename := pos.Filename
-					suppressedLine := pos.Line + 1
+					suppressedLine := pos.Line
+					if ctx.startsLine(comment.Pos()) {
+						suppressedLine++
+					}
 					if ann.nolint[filename] == nil {
-						ann.nolint[filename] = make(map[int]bool)
+						ann.nolint[filename] = make(map[int][]*suppression)
 					}
-					ann.nolint[filename][suppressedLine] = true
+					ann.nolint[filename][suppressedLine] = append(ann.nolint[filename][suppressedLine], sup)
+
+				case "requires", "acquires", "releases":
+					ctx.parseContract(ann, funcDecls, comment, d)
+
+				case "guardedby", "unguarded":
+					ctx.parseFieldGuard(ann, fieldComments[comment], comment, d)
 				}
 			}
 		}
@@ -66,6 +145,115 @@ func (ctx *passContext) parseAnnotations() {
 	ctx.annotations = ann
 }
 
+// parseSuppression validates the checks of a //mu:ignore or //mu:nolint
+// directive. Unknown catalog IDs are reported and invalidate the directive.
+// With -require-reason, a missing reason is reported too.
+func (ctx *passContext) parseSuppression(comment *ast.Comment, d directive) (*suppression, bool) {
+	sup := &suppression{comment: comment, text: d.text}
+	if d.checks != nil {
+		if len(d.checks) == 0 {
+			ctx.reportDirective(comment.Pos(), "//%s directive has an empty check list", d.text)
+			return nil, false
+		}
+		sup.checks = make(map[string]bool)
+		for _, id := range d.checks {
+			if _, ok := LookupCheck(id); !ok {
+				ctx.reportDirective(comment.Pos(), "//%s directive names unknown check %q", d.text, id)
+				return nil, false
+			}
+			sup.checks[id] = true
+		}
+	}
+	if ctx.requireReason && d.reason == "" {
+		ctx.reportDirective(comment.Pos(), "//%s directive has no reason \u2014 explain why after it (//%s // reason)", d.text, d.text)
+	}
+	return sup, true
+}
+
+// structFieldComments maps the comments of the struct fields declared in file
+// (doc comments and trailing comments) to the fields they annotate.
+func (ctx *passContext) structFieldComments(file *ast.File) map[*ast.Comment][]fieldKey {
+	result := make(map[*ast.Comment][]fieldKey)
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return true
+		}
+		obj, ok := ctx.pass.TypesInfo.Defs[spec.Name].(*types.TypeName)
+		if !ok {
+			return true
+		}
+		named, ok := obj.Type().(*types.Named)
+		if !ok {
+			return true
+		}
+		index := 0
+		for _, field := range st.Fields.List {
+			var keys []fieldKey
+			for range max(len(field.Names), 1) {
+				keys = append(keys, fieldKey{StructType: named, FieldIndex: index})
+				index++
+			}
+			for _, cg := range []*ast.CommentGroup{field.Doc, field.Comment} {
+				if cg == nil {
+					continue
+				}
+				for _, c := range cg.List {
+					result[c] = keys
+				}
+			}
+		}
+		return true
+	})
+	return result
+}
+
+// parseFieldGuard records a //mu:guardedby or //mu:unguarded directive, which
+// must annotate struct fields. //mu:guardedby names a mutex field of the same
+// struct, optionally followed by a reason. Invalid directives are reported
+// and ignored.
+func (ctx *passContext) parseFieldGuard(ann *annotations, fields []fieldKey, comment *ast.Comment, d directive) {
+	if len(fields) == 0 {
+		ctx.reportDirective(comment.Pos(), "//%s directive must annotate a struct field", d.text)
+		return
+	}
+	if d.checks != nil {
+		ctx.reportDirective(comment.Pos(), "//%s directive takes no check list", d.text)
+		return
+	}
+	guard := -1
+	if d.name == "guardedby" {
+		args := strings.Fields(d.args)
+		if len(args) == 0 || strings.HasPrefix(args[0], "//") {
+			ctx.reportDirective(comment.Pos(), "//%s directive names no mutex, e.g. //%s mu", d.text, d.text)
+			return
+		}
+		named := fields[0].StructType
+		st := named.Underlying().(*types.Struct)
+		for i := 0; i < st.NumFields(); i++ {
+			if st.Field(i).Name() == args[0] {
+				guard = i
+				break
+			}
+		}
+		if guard < 0 {
+			ctx.reportDirective(comment.Pos(), "//%s directive: %s has no field %s", d.text, named.Obj().Name(), args[0])
+			return
+		}
+		if !ctx.isMutexType(st.Field(guard).Type()) {
+			ctx.reportDirective(comment.Pos(), "//%s directive: %s.%s is not a mutex", d.text, named.Obj().Name(), args[0])
+			return
+		}
+	}
+	for _, key := range fields {
+		ann.fieldGuards[key] = guard
+	}
+}
+
 // findFuncForComment finds the SSA function corresponding to the function
 // declaration that contains or immediately follows the comment at commentPos.
 func (ctx *passContext) findFuncForComment(fset *token.FileSet, funcDecls []*ast.FuncDecl, commentPos token.Pos) *ssa.Function {
@@ -105,23 +293,69 @@ func (ctx *passContext) astFuncToSSA(fd *ast.FuncDecl) *ssa.Function {
 	return nil
 }
 
-// isSuppressed returns true if reporting should be suppressed for the given
-// function and position, either because the function has //mu:ignore or the
-// line has //mu:nolint on the preceding line.
-func (ctx *passContext) isSuppressed(fn *ssa.Function, pos token.Pos) bool {
+// isSuppressed returns true if reporting check should be suppressed for the
+// given function and position, either because the function has //mu:ignore or
+// the line has //mu:nolint, on the preceding line or as a trailing comment,
+// for that check or for all checks. Matching directives are marked used.
+func (ctx *passContext) isSuppressed(fn *ssa.Function, pos token.Pos, check string) bool {
 	if ctx.annotations == nil {
 		return false
 	}
-	if ctx.annotations.ignored[fn] {
-		return true
+	suppressed := false
+	use := func(sups []*suppression) {
+		for _, sup := range sups {
+			if sup.covers(check) {
+				sup.used = true
+				suppressed = true
+			}
+		}
 	}
+	use(ctx.annotations.ignored[fn])
 	if pos.IsValid() {
 		p := ctx.pass.Fset.Position(pos)
-		if lines, ok := ctx.annotations.nolint[p.Filename]; ok {
-			if lines[p.Line] {
-				return true
-			}
+		use(ctx.annotations.nolint[p.Filename][p.Line])
+	}
+	return suppressed
+}
+
+// reportUnusedSuppressions reports the //mu:ignore and //mu:nolint directives
+// that suppressed no diagnostic, with a fix deleting them
+// (-report-unused-suppressions). Directives whose checks are all disabled are
+// skipped: they may be needed once the checks are enabled.
+func (ctx *passContext) reportUnusedSuppressions() {
+	if !ctx.reportUnused || ctx.annotations == nil {
+		return
+	}
+	for _, sup := range ctx.annotations.suppressions {
+		if sup.used || !ctx.anyEnabled(ctx.pkgAt(sup.comment.Pos()), sup.checks) {
+			continue
+		}
+		ctx.reportWithFixes(sup.comment.Pos(), Finding{
+			Check:   DirectiveCheck.ID,
+			Message: fmt.Sprintf("//%s directive suppresses no diagnostic", sup.text),
+		}, ctx.deleteCommentFix(sup.comment))
+	}
+}
+
+// anyEnabled reports whether a check of the set is enabled in pkg; a nil set
+// stands for all checks.
+func (ctx *passContext) anyEnabled(pkg *types.Package, checks map[string]bool) bool {
+	if checks == nil {
+		return true
+	}
+	sel := ctx.checksOf(pkg)
+	for id := range checks {
+		if sel.enabled(id) {
+			return true
 		}
 	}
 	return false
 }
+
+// reportDirective reports a malformed or unjustified directive.
+func (ctx *passContext) reportDirective(pos token.Pos, format string, args ...any) {
+	ctx.report(pos, Finding{
+		Check:   DirectiveCheck.ID,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
@@ -0,0 +1,128 @@
+package analyzer
+
+import (
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// atomicAccessKind reports whether callee is a sync/atomic function operating
+// on an address (atomic.AddInt64(&s.n, 1), atomic.LoadPointer(&s.p), ...),
+// and whether the access is a read (Load*) or a write.
+func atomicAccessKind(callee *ssa.Function) (isRead bool, ok bool) {
+	if callee.Signature.Recv() != nil {
+		return false, false
+	}
+	obj, isFunc := callee.Object().(*types.Func)
+	if !isFunc || obj.Pkg() == nil || obj.Pkg().Path() != "sync/atomic" {
+		return false, false
+	}
+	name := obj.Name()
+	switch {
+	case strings.HasPrefix(name, "Load"):
+		return true, true
+	case strings.HasPrefix(name, "Store"),
+		strings.HasPrefix(name, "Add"),
+		strings.HasPrefix(name, "Swap"),
+		strings.HasPrefix(name, "CompareAndSwap"),
+		strings.HasPrefix(name, "And"),
+		strings.HasPrefix(name, "Or"):
+		return false, true
+	}
+	return false, false
+}
+
+// isAtomicType returns true if t is one of the sync/atomic value types
+// (atomic.Int64, atomic.Pointer[T], atomic.Value, ...). Fields of these types
+// are self-synchronized and excluded from guard inference.
+func isAtomicType(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj != nil && obj.Pkg() != nil && obj.Pkg().Path() == "sync/atomic"
+}
+
+// processAtomicAccess records an atomic observation for a sync/atomic call
+// whose address argument is a struct field.
+func (ctx *passContext) processAtomicAccess(fn *ssa.Function, addr ssa.Value, isRead bool, pos token.Pos, ls *lockState) {
+	base, fieldIdx, structType, ok := resolveFieldAccess(addr)
+	if !ok {
+		return
+	}
+	st, stOk := structType.Underlying().(*types.Struct)
+	if !stOk || fieldIdx >= st.NumFields() {
+		return
+	}
+	if ctx.isMutexType(st.Field(fieldIdx).Type()) {
+		return
+	}
+
+	key := fieldKey{StructType: structType, FieldIndex: fieldIdx}
+	ok2 := obsKey{field: key, pos: pos, isRead: isRead}
+	if ctx.observedAt[ok2] {
+		return
+	}
+	ctx.observedAt[ok2] = true
+	ctx.observations[key] = append(ctx.observations[key], observation{
+		SameBaseMutexFields: sameBaseMutexFields(base, ls),
+		IsRead:              isRead,
+		IsAtomic:            true,
+		Func:                fn,
+		Pos:                 pos,
+	})
+	ctx.atomicFields[key] = true
+}
+
+// checkMixedAtomicAccess reports plain accesses to fields that are accessed
+// through sync/atomic elsewhere. A plain access is only safe if it holds the
+// field's inferred guard; otherwise it races with the atomic accesses.
+func (ctx *passContext) checkMixedAtomicAccess() {
+	for key := range ctx.atomicFields {
+		if ctx.fieldExcluded(key) || ctx.isUnguardedField(key) {
+			continue
+		}
+		var firstAtomic token.Pos
+		var plain []observation
+		for _, obs := range ctx.observations[key] {
+			if ctx.isInitializationContext(obs.Func, key.StructType) {
+				continue
+			}
+			if obs.IsAtomic {
+				if !firstAtomic.IsValid() || obs.Pos < firstAtomic {
+					firstAtomic = obs.Pos
+				}
+				continue
+			}
+			plain = append(plain, obs)
+		}
+		if !firstAtomic.IsValid() {
+			continue
+		}
+
+		guard, hasGuard := ctx.guards[key]
+		for _, obs := range plain {
+			if !ctx.isConcurrent(obs.Func) {
+				continue
+			}
+			if hasGuard && holdsMutexField(obs, guard.MutexFieldIndex) {
+				continue
+			}
+			ctx.reportMixedAtomicAccess(obs, key, firstAtomic)
+		}
+	}
+}
+
+// holdsMutexField returns true if the observation was made while the given
+// mutex field was held on the same struct instance.
+func holdsMutexField(obs observation, mutexFieldIndex int) bool {
+	for _, hmf := range obs.SameBaseMutexFields {
+		if hmf.FieldIndex == mutexFieldIndex {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Baselines record the findings of a codebase so that only new ones are
+// reported. Findings are matched by fingerprint, which ignores positions: a
+// baselined finding stays suppressed when code around it moves. Each entry
+// counts the findings sharing its fingerprint, so that a new violation in a
+// function that already had one is still reported.
+
+var baselinePath string
+
+func init() {
+	Analyzer.Flags.StringVar(&baselinePath, "baseline", "", "suppress the findings recorded in this baseline file (see golintmu -write-baseline)")
+}
+
+// baselineVersion is bumped on incompatible changes to the baseline file or
+// to fingerprints.
+const baselineVersion = 1
+
+// Baseline is the content of a baseline file.
+type Baseline struct {
+	Version  int             `json:"version"`
+	Findings []BaselineEntry `json:"findings"`
+}
+
+// BaselineEntry records the findings sharing a fingerprint. The fields other
+// than Fingerprint and Count are informational, for reviewers of the file.
+type BaselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Count       int    `json:"count"`
+	Check       string `json:"check"`
+	Package     string `json:"package"`
+	Func        string `json:"func,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Field       string `json:"field,omitempty"`
+	Variable    string `json:"variable,omitempty"`
+	Mutex       string `json:"mutex,omitempty"`
+	Message     string `json:"message"` // message of the first finding
+}
+
+// Fingerprint identifies f independently of its position: package, catalog
+// ID, struct type, field or variable, mutex and enclosing function.
+func (f Finding) Fingerprint() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		f.Package, f.Check, f.Type, f.Field, f.Variable, f.Mutex, f.Func,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// NewBaseline returns a baseline recording findings.
+func NewBaseline(findings []Finding) *Baseline {
+	b := &Baseline{Version: baselineVersion, Findings: []BaselineEntry{}}
+	index := make(map[string]int)
+	for _, f := range findings {
+		fp := f.Fingerprint()
+		if i, ok := index[fp]; ok {
+			b.Findings[i].Count++
+			continue
+		}
+		index[fp] = len(b.Findings)
+		b.Findings = append(b.Findings, BaselineEntry{
+			Fingerprint: fp,
+			Count:       1,
+			Check:       f.Check,
+			Package:     f.Package,
+			Func:        f.Func,
+			Type:        f.Type,
+			Field:       f.Field,
+			Variable:    f.Variable,
+			Mutex:       f.Mutex,
+			Message:     f.Message,
+		})
+	}
+	sort.Slice(b.Findings, func(i, j int) bool {
+		a, c := b.Findings[i], b.Findings[j]
+		if a.Package != c.Package {
+			return a.Package < c.Package
+		}
+		if a.Func != c.Func {
+			return a.Func < c.Func
+		}
+		if a.Check != c.Check {
+			return a.Check < c.Check
+		}
+		return a.Fingerprint < c.Fingerprint
+	})
+	return b
+}
+
+// Write prints the baseline as indented JSON.
+func (b *Baseline) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// ReadBaseline reads a baseline file.
+func ReadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if b.Version != baselineVersion {
+		return nil, fmt.Errorf("%s: unsupported baseline version %d (want %d), regenerate it with -write-baseline", path, b.Version, baselineVersion)
+	}
+	return &b, nil
+}
+
+// counts returns the number of baselined findings of each fingerprint.
+func (b *Baseline) counts() map[string]int {
+	counts := make(map[string]int, len(b.Findings))
+	for _, e := range b.Findings {
+		counts[e.Fingerprint] += e.Count
+	}
+	return counts
+}
+
+// baselineCacheEntry is the result of reading a baseline file.
+type baselineCacheEntry struct {
+	counts map[string]int
+	err    error
+}
+
+// baselineCache caches baseline files by path: every pass of a run reads the
+// same file.
+var baselineCache sync.Map // string → baselineCacheEntry
+
+// loadBaseline returns the fingerprint counts of the baseline file at path,
+// or nil when path is empty. The result is shared and must not be modified.
+func loadBaseline(path string) (map[string]int, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if v, ok := baselineCache.Load(path); ok {
+		e := v.(baselineCacheEntry)
+		return e.counts, e.err
+	}
+	var counts map[string]int
+	b, err := ReadBaseline(path)
+	if err == nil {
+		counts = b.counts()
+	}
+	baselineCache.Store(path, baselineCacheEntry{counts, err})
+	return counts, err
+}
+
+// inBaseline reports whether f is covered by the baseline, consuming one
+// occurrence of its fingerprint for the current package.
+func (ctx *passContext) inBaseline(f Finding) bool {
+	if ctx.baseline == nil {
+		return false
+	}
+	fp := f.Fingerprint()
+	if ctx.baselineUsed[fp] >= ctx.baseline[fp] {
+		return false
+	}
+	ctx.baselineUsed[fp]++
+	return true
+}
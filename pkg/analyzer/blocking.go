@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// blockingOrigin records why a function may block.
+// Either a direct blocking operation (Desc set) or a call to a callee that
+// may block (ViaCallee non-nil).
+type blockingOrigin struct {
+	// For direct blocking: the operation and its position.
+	Desc string
+	Pos  token.Pos
+
+	// For transitive blocking: the callee that may block.
+	ViaCallee  *ssa.Function
+	ViaCallPos token.Pos
+}
+
+// blockingOpCandidate records a potential C9 diagnostic collected during
+// Phase 1 (SSA walk). Reporting is deferred to Phase 3.9.7 so that the
+// concurrent context (Phase 3.5) is available for filtering.
+type blockingOpCandidate struct {
+	Fn   *ssa.Function
+	Pos  token.Pos
+	Ref  lockRef
+	Desc string // human-readable operation, e.g. "channel send"
+}
+
+// blockingCall describes a well-known blocking function or method.
+type blockingCall struct {
+	pkgPath  string
+	recvName string // named receiver type; empty for package-level functions
+	name     string
+	desc     string
+}
+
+// blockingStaticCalls lists statically dispatched calls known to block.
+var blockingStaticCalls = []blockingCall{
+	{pkgPath: "time", name: "Sleep", desc: "time.Sleep()"},
+	{pkgPath: "sync", recvName: "WaitGroup", name: "Wait", desc: "sync.WaitGroup.Wait()"},
+	{pkgPath: "net/http", recvName: "Client", name: "Do", desc: "http.Client.Do()"},
+}
+
+// blockingInterfaceCalls lists interface method calls known to block.
+var blockingInterfaceCalls = []blockingCall{
+	{pkgPath: "net", recvName: "Conn", name: "Read", desc: "net.Conn.Read()"},
+	{pkgPath: "net", recvName: "Conn", name: "Write", desc: "net.Conn.Write()"},
+	{pkgPath: "net", recvName: "Listener", name: "Accept", desc: "net.Listener.Accept()"},
+	{pkgPath: "io", recvName: "Reader", name: "Read", desc: "io.Reader.Read()"},
+}
+
+// blockingStaticCallDesc returns a description of callee if it is a known
+// blocking function or method.
+func blockingStaticCallDesc(callee *ssa.Function) (string, bool) {
+	obj, ok := callee.Object().(*types.Func)
+	if !ok || obj.Pkg() == nil {
+		return "", false
+	}
+	recvName := ""
+	if recv := callee.Signature.Recv(); recv != nil {
+		recvName = namedTypeName(recv.Type())
+	}
+	for _, bc := range blockingStaticCalls {
+		if bc.pkgPath == obj.Pkg().Path() && bc.recvName == recvName && bc.name == obj.Name() {
+			return bc.desc, true
+		}
+	}
+	return "", false
+}
+
+// blockingInvokeDesc returns a description of an interface method call if the
+// interface and method are known to block. The method is matched by the
+// interface declaring it, so that interfaces embedding a blocking one (e.g.
+// io.ReadCloser, or a custom interface embedding net.Conn) match too.
+func blockingInvokeDesc(common *ssa.CallCommon) (string, bool) {
+	method := common.Method.Origin()
+	recv := method.Type().(*types.Signature).Recv()
+	if recv == nil || method.Pkg() == nil {
+		return "", false
+	}
+	recvName := namedTypeName(recv.Type())
+	for _, bc := range blockingInterfaceCalls {
+		if bc.pkgPath == method.Pkg().Path() && bc.recvName == recvName && bc.name == method.Name() {
+			return bc.desc, true
+		}
+	}
+	return "", false
+}
+
+// namedTypeName returns the name of t (or *t) if it is a named type.
+func namedTypeName(t types.Type) string {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if named, ok := t.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return ""
+}
+
+// recordBlockingOp marks fn as possibly blocking and records a C9 candidate
+// for each lock held at the blocking operation. Candidates are keyed by
+// position to clear stale entries on block re-walks.
+func (ctx *passContext) recordBlockingOp(fn *ssa.Function, pos token.Pos, desc string, ls *lockState) {
+	facts := ctx.getOrCreateFuncFacts(fn)
+	if !facts.MayBlock {
+		facts.MayBlock = true
+		if ctx.verbose {
+			facts.MayBlockOrigin = &blockingOrigin{Desc: desc, Pos: pos}
+		}
+	}
+
+	delete(ctx.blockingOpCandidates, pos)
+	var candidates []blockingOpCandidate
+	for ref := range ls.held {
+		candidates = append(candidates, blockingOpCandidate{
+			Fn:   fn,
+			Pos:  pos,
+			Ref:  ref,
+			Desc: desc,
+		})
+	}
+	if len(candidates) > 0 {
+		ctx.blockingOpCandidates[pos] = candidates
+	}
+}
+
+// propagateMayBlock propagates MayBlock bottom-up through the call graph:
+// a function that calls a possibly blocking callee may itself block.
+func (ctx *passContext) propagateMayBlock() {
+	const maxIterations = 1000
+	changed := true
+	for i := 0; changed && i < maxIterations; i++ {
+		changed = false
+		for _, cs := range ctx.callSites {
+			calleeFacts, ok := ctx.funcFacts[cs.Callee]
+			if !ok || !calleeFacts.MayBlock {
+				continue
+			}
+			callerFacts := ctx.getOrCreateFuncFacts(cs.Caller)
+			if callerFacts.MayBlock {
+				continue
+			}
+			callerFacts.MayBlock = true
+			if ctx.verbose {
+				callerFacts.MayBlockOrigin = &blockingOrigin{ViaCallee: cs.Callee, ViaCallPos: cs.Pos}
+			}
+			changed = true
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package analyzer
+
+// Check describes an entry of the diagnostic catalog (docs/catalog). The
+// catalog ID is the Category of every diagnostic and the Check of every
+// Finding.
+type Check struct {
+	ID          string // catalog ID, "C1".."C16"
+	Name        string // short title
+	Severity    string // default severity: "error" or "warning"
+	Description string // one-line description of the bug class
+	Doc         string // catalog page, relative to the repository root
+}
+
+// Severities used in the catalog.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityNote    = "note" // advisory, only set through -severity
+)
+
+// Catalog lists the checks performed by the analyzer, in catalog order. It
+// mirrors the table in docs/design.md §3.
+var Catalog = []Check{
+	{"C1", "Inconsistent field locking", SeverityError, "Field accessed under lock in some paths, without in others", "docs/catalog/C01-inconsistent-field-locking.md"},
+	{"C2", "Double locking", SeverityError, "Mutex locked when already held — immediate deadlock", "docs/catalog/C02-double-locking.md"},
+	{"C3", "Lock ordering violations", SeverityError, "Inconsistent acquisition order across code paths — potential deadlock", "docs/catalog/C03-lock-ordering.md"},
+	{"C4", "Unlock of unlocked mutex", SeverityError, "Unlock() when mutex isn't held — runtime panic", "docs/catalog/C04-unlock-of-unlocked.md"},
+	{"C5", "Lock leak / missing unlock", SeverityError, "Function returns without unlocking on some code path", "docs/catalog/C05-lock-leak.md"},
+	{"C6", "RWMutex misuse", SeverityError, "Mismatched unlock, recursive RLock, lock upgrade attempt, write under RLock", "docs/catalog/C06-rwmutex-misuse.md"},
+	{"C7", "Deferred Lock instead of Unlock", SeverityError, "defer mu.Lock() typo — deadlock at function exit", "docs/catalog/C07-deferred-lock.md"},
+	{"C8", "Lock held across goroutine spawn", SeverityWarning, "Goroutine spawned while lock is held", "docs/catalog/C08-lock-across-goroutine.md"},
+	{"C9", "Lock held across blocking ops", SeverityWarning, "Channel/sleep/I/O while lock is held", "docs/catalog/C09-lock-across-blocking.md"},
+	{"C10", "Mutex copying", SeverityError, "Mutex copied by value — breaks synchronization", "docs/catalog/C10-mutex-copying.md"},
+	{"C11", "Inconsistent branch locking", SeverityError, "Lock held in one branch but not the other at merge point", "docs/catalog/C11-inconsistent-branch-locking.md"},
+	{"C12", "Cross-goroutine unlock", SeverityWarning, "Lock/unlock in different goroutines — fragile pattern", "docs/catalog/C12-cross-goroutine-unlock.md"},
+	{"C13", "Return while holding lock", SeverityWarning, "Function returns with lock held, caller unaware", "docs/catalog/C13-return-while-locked.md"},
+	{"C14", "Exported guarded field", SeverityWarning, "Guarded field is exported — external callers can bypass lock", "docs/catalog/C14-exported-guarded-field.md"},
+	{"C15", "Lock contract violation", SeverityError, "Function body contradicts its //mu:requires, //mu:acquires or //mu:releases contract", "docs/catalog/C15-lock-contract-violation.md"},
+	{"C16", "Mixed atomic access", SeverityError, "Field accessed through sync/atomic in some paths, plainly in others", "docs/catalog/C16-mixed-atomic-access.md"},
+}
+
+// DirectiveCheck is the category of diagnostics about //mu: directives
+// themselves (unknown check IDs, missing reasons). It is not a bug class and
+// is not part of the catalog, but can be selected and configured like one.
+var DirectiveCheck = Check{"directive", "Invalid directive", SeverityWarning, "Malformed or unjustified //mu: directive", "README.md#annotations"}
+
+// LookupCheck returns the catalog entry for a catalog ID, or DirectiveCheck.
+func LookupCheck(id string) (Check, bool) {
+	for _, c := range Catalog {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	if id == DirectiveCheck.ID {
+		return DirectiveCheck, true
+	}
+	return Check{}, false
+}
@@ -0,0 +1,123 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Check selection (-checks) and severity overrides (-severity). Both flags
+// take catalog IDs; they are validated when parsed so that a typo fails the
+// run instead of silently disabling nothing.
+
+var (
+	checks     = checkSelection{}
+	severities = severityMap{}
+)
+
+func init() {
+	Analyzer.Flags.Var(&checks, "checks", "comma-separated catalog IDs to report, e.g. C1,C3; -ID disables a check (-C14); default all")
+	Analyzer.Flags.Var(&severities, "severity", "comma-separated severity overrides, e.g. C13=note,C14=note (error, warning or note)")
+}
+
+// checkSelection is the parsed -checks flag. When only is non-nil, checks not
+// in it are disabled; checks in disabled are always disabled.
+type checkSelection struct {
+	only     map[string]bool
+	disabled map[string]bool
+	raw      string
+}
+
+func (s *checkSelection) String() string { return s.raw }
+
+func (s *checkSelection) Set(value string) error {
+	sel := checkSelection{raw: value}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, disable := strings.CutPrefix(item, "-")
+		if id == "all" && !disable {
+			continue
+		}
+		if _, ok := LookupCheck(id); !ok {
+			return fmt.Errorf("unknown check %q", id)
+		}
+		if disable {
+			if sel.disabled == nil {
+				sel.disabled = make(map[string]bool)
+			}
+			sel.disabled[id] = true
+			continue
+		}
+		if sel.only == nil {
+			sel.only = make(map[string]bool)
+		}
+		sel.only[id] = true
+	}
+	*s = sel
+	return nil
+}
+
+// enabled reports whether the check with catalog ID id is selected.
+func (s *checkSelection) enabled(id string) bool {
+	if s.disabled[id] {
+		return false
+	}
+	return s.only == nil || s.only[id]
+}
+
+// severityMap is the parsed -severity flag: catalog ID → severity.
+type severityMap map[string]string
+
+func (m *severityMap) String() string {
+	var items []string
+	for id, sev := range *m {
+		items = append(items, id+"="+sev)
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+func (m *severityMap) Set(value string) error {
+	parsed := severityMap{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, sev, ok := strings.Cut(item, "=")
+		if !ok {
+			return fmt.Errorf("invalid severity %q, want ID=severity", item)
+		}
+		if _, ok := LookupCheck(id); !ok {
+			return fmt.Errorf("unknown check %q", id)
+		}
+		switch sev {
+		case SeverityError, SeverityWarning, SeverityNote:
+		default:
+			return fmt.Errorf("invalid severity %q for %s, want error, warning or note", sev, id)
+		}
+		parsed[id] = sev
+	}
+	*m = parsed
+	return nil
+}
+
+// Severity returns the effective severity of the check with catalog ID id:
+// the -severity override if any, the catalog default otherwise.
+func Severity(id string) string {
+	return severities.severity(id)
+}
+
+// severity returns the effective severity of the check id under m.
+func (m severityMap) severity(id string) string {
+	if sev, ok := m[id]; ok {
+		return sev
+	}
+	if c, ok := LookupCheck(id); ok {
+		return c.Severity
+	}
+	return SeverityError
+}
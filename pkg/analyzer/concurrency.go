@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"go/token"
 	"go/types"
 
 	"golang.org/x/tools/go/ssa"
@@ -9,11 +10,27 @@ import (
 // computeConcurrentContext detects concurrent entrypoints and computes the set
 // of functions reachable from them. If no entrypoints are detected, all
 // functions are treated as concurrent (conservative fallback).
+//
+// Callbacks invoked from the concurrent context make more parameters run
+// concurrently (see concurrentparams.go), and the callbacks passed to them
+// more entrypoints: detection and reachability are repeated until no
+// concurrent parameter is added.
 func (ctx *passContext) computeConcurrentContext() {
-	entrypoints := ctx.detectConcurrentEntrypoints()
+	ctx.collectConcurrentParams()
+	for {
+		ctx.entrypoints = ctx.detectConcurrentEntrypoints()
+		ctx.concurrentFuncs = ctx.reachableFrom(ctx.entrypoints)
+		if !ctx.collectConcurrentParams() {
+			return
+		}
+	}
+}
+
+// reachableFrom returns the functions reachable from entrypoints through the
+// call graph, or nil (all concurrent) when there are no entrypoints.
+func (ctx *passContext) reachableFrom(entrypoints map[*ssa.Function]bool) map[*ssa.Function]bool {
 	if len(entrypoints) == 0 {
-		ctx.concurrentFuncs = nil // nil = all concurrent
-		return
+		return nil // nil = all concurrent
 	}
 
 	// BFS reachability from entrypoints through the call graph.
@@ -33,8 +50,7 @@ func (ctx *passContext) computeConcurrentContext() {
 			}
 		}
 	}
-
-	ctx.concurrentFuncs = reachable
+	return reachable
 }
 
 // isConcurrent returns true if fn runs in a concurrent context.
@@ -47,9 +63,21 @@ func (ctx *passContext) isConcurrent(fn *ssa.Function) bool {
 }
 
 // detectConcurrentEntrypoints scans source functions for concurrent patterns:
-// - Functions launched via `go` statements
-// - ServeHTTP methods with the correct signature
-// - Functions passed to http.HandleFunc / (*http.ServeMux).HandleFunc
+//   - Functions launched via `go` statements
+//   - ServeHTTP methods with the correct signature
+//   - HTTP handlers passed to net/http (http.Handle, http.HandleFunc,
+//     (*http.ServeMux).Handle, ...), converted to http.HandlerFunc or set as
+//     the Handler of an http.Server, through middleware constructors
+//   - Callbacks run on another goroutine by the standard library
+//     (time.AfterFunc, runtime.SetFinalizer, ...) and by Go methods like
+//     (*sync.WaitGroup).Go and (*errgroup.Group).Go
+//   - Callbacks passed to functions running them concurrently, in this package
+//     or imported (ConcurrentFact)
+//   - Callbacks passed to the arguments configured in entrypointArgs, and
+//     methods of types implementing the configured entrypointInterfaces
+//   - gRPC and ConnectRPC service methods (see services.go)
+//   - Functions matching an entrypoint pattern of the configuration
+//   - With -exported-entrypoints, exported methods of types with a mutex field
 func (ctx *passContext) detectConcurrentEntrypoints() map[*ssa.Function]bool {
 	entrypoints := make(map[*ssa.Function]bool)
 
@@ -59,6 +87,17 @@ func (ctx *passContext) detectConcurrentEntrypoints() map[*ssa.Function]bool {
 			entrypoints[fn] = true
 		}
 
+		// Entrypoints listed in the configuration.
+		if ctx.configOf(ctx.pkgOf(fn)).isEntrypoint(fn) {
+			entrypoints[fn] = true
+		}
+
+		// Library API: any exported method of a type with a mutex may be
+		// called concurrently.
+		if ctx.exportedEntrypoints && ctx.isExportedMutexMethod(fn) {
+			entrypoints[fn] = true
+		}
+
 		if len(fn.Blocks) == 0 {
 			continue
 		}
@@ -71,7 +110,24 @@ func (ctx *passContext) detectConcurrentEntrypoints() map[*ssa.Function]bool {
 						entrypoints[target] = true
 					}
 				case *ssa.Call:
-					if target := extractHandlerFuncTarget(inst); target != nil {
+					for _, target := range ctx.httpHandlerTargets(inst) {
+						entrypoints[target] = true
+					}
+					for _, target := range ctx.serviceRegistrationTargets(inst) {
+						entrypoints[target] = true
+					}
+					for _, target := range ctx.callbackTargets(inst) {
+						entrypoints[target] = true
+					}
+				case *ssa.ChangeType:
+					// http.HandlerFunc(fn): fn serves HTTP requests.
+					if isNetHTTPType(inst.Type(), "HandlerFunc") {
+						for _, target := range ctx.resolveHTTPHandler(inst.X, nil, make(map[ssa.Value]bool)) {
+							entrypoints[target] = true
+						}
+					}
+				case *ssa.Store:
+					for _, target := range ctx.httpServerHandlerTargets(inst) {
 						entrypoints[target] = true
 					}
 				}
@@ -79,6 +135,16 @@ func (ctx *passContext) detectConcurrentEntrypoints() map[*ssa.Function]bool {
 		}
 	}
 
+	// Service methods of types embedding an Unimplemented service type.
+	for _, target := range ctx.embeddedServiceTargets() {
+		entrypoints[target] = true
+	}
+
+	// Methods of types implementing a configured entrypoint interface.
+	for _, target := range ctx.configuredInterfaceTargets() {
+		entrypoints[target] = true
+	}
+
 	// Merge in functions annotated with //mu:concurrent.
 	if ctx.annotations != nil {
 		for fn := range ctx.annotations.concurrent {
@@ -89,6 +155,77 @@ func (ctx *passContext) detectConcurrentEntrypoints() map[*ssa.Function]bool {
 	return entrypoints
 }
 
+// detectCrossGoroutineUnlocks matches locks held at a go statement against
+// unlocks of the same mutex performed by the spawned goroutine. The
+// goroutine context of a spawn is the set of functions reachable from its go
+// target. An unlock of a lock not held locally (a C4 candidate) inside that
+// context releases the parent's lock from another goroutine: it is reported as
+// C12 instead of C4, and the parent's leak of that lock is an intentional
+// handoff rather than C5/C8/C13.
+func (ctx *passContext) detectCrossGoroutineUnlocks() {
+	if len(ctx.goroutineSpawnCandidates) == 0 || len(ctx.unlockOfUnlockedCandidates) == 0 {
+		return
+	}
+
+	forward := ctx.buildForwardCallGraph()
+	goroutineContexts := make(map[*ssa.Function]map[*ssa.Function]bool)
+
+	for _, candidates := range ctx.goroutineSpawnCandidates {
+		for _, spawn := range candidates {
+			if spawn.Target == nil {
+				continue
+			}
+			heldKey, ok := lockRefToMutexFieldKey(&spawn.Ref)
+			if !ok {
+				continue
+			}
+			reach, ok := goroutineContexts[spawn.Target]
+			if !ok {
+				reach = reachableFrom(forward, spawn.Target)
+				goroutineContexts[spawn.Target] = reach
+			}
+
+			for _, unlock := range ctx.unlockOfUnlockedCandidates {
+				if !reach[unlock.Fn] {
+					continue
+				}
+				unlockKey, ok := lockRefToMutexFieldKey(&unlock.Ref)
+				if !ok || unlockKey != heldKey {
+					continue
+				}
+				key := handedOffUnlockKey{fn: unlock.Fn, pos: unlock.Pos}
+				if !ctx.handedOffUnlocks[key] {
+					ctx.handedOffUnlocks[key] = true
+					ctx.crossGoroutineUnlocks = append(ctx.crossGoroutineUnlocks, crossGoroutineUnlock{
+						Fn:         unlock.Fn,
+						Pos:        unlock.Pos,
+						Ref:        unlock.Ref,
+						SpawnPos:   spawn.Pos,
+						AcquirePos: spawn.AcquirePos,
+					})
+				}
+				ctx.lockHandoffs[lockHandoffKey{fn: spawn.Fn, ref: spawn.Ref}] = true
+			}
+		}
+	}
+}
+
+// reachableFrom returns the set of functions reachable from root through the
+// forward call graph, including root itself.
+func reachableFrom(forward map[*ssa.Function][]*ssa.Function, root *ssa.Function) map[*ssa.Function]bool {
+	reachable := map[*ssa.Function]bool{root: true}
+	queue := []*ssa.Function{root}
+	for head := 0; head < len(queue); head++ {
+		for _, callee := range forward[queue[head]] {
+			if !reachable[callee] {
+				reachable[callee] = true
+				queue = append(queue, callee)
+			}
+		}
+	}
+	return reachable
+}
+
 // buildForwardCallGraph builds caller → []callee from recorded call sites.
 func (ctx *passContext) buildForwardCallGraph() map[*ssa.Function][]*ssa.Function {
 	forward := make(map[*ssa.Function][]*ssa.Function)
@@ -114,6 +251,21 @@ func isServeHTTPMethod(fn *ssa.Function) bool {
 	return isHTTPResponseWriter(params.At(0).Type()) && isHTTPRequestPtr(params.At(1).Type())
 }
 
+// isExportedMutexMethod returns true if fn is an exported method declared on a
+// struct type with a direct mutex field (see mutexFieldIndices).
+func (ctx *passContext) isExportedMutexMethod(fn *ssa.Function) bool {
+	recv := fn.Signature.Recv()
+	if recv == nil || fn.Parent() != nil || fn.Synthetic != "" || !token.IsExported(fn.Name()) {
+		return false
+	}
+	named := receiverNamed(recv.Type())
+	if named == nil {
+		return false
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	return ok && len(ctx.mutexFieldIndices(st)) > 0
+}
+
 // extractGoTarget extracts the function launched by a `go` statement.
 func extractGoTarget(goInstr *ssa.Go) *ssa.Function {
 	common := goInstr.Common()
@@ -134,74 +286,326 @@ func extractGoTarget(goInstr *ssa.Go) *ssa.Function {
 	return nil
 }
 
-// extractHandlerFuncTarget extracts function arguments passed to
-// http.HandleFunc or (*http.ServeMux).HandleFunc.
-func extractHandlerFuncTarget(call *ssa.Call) *ssa.Function {
-	common := call.Common()
-	callee := common.StaticCallee()
-	if callee == nil {
+// httpHandlerTargets returns the handlers passed to a net/http function or
+// method: http.Handle, http.HandleFunc, (*http.ServeMux).Handle,
+// http.ListenAndServe, http.StripPrefix, ...
+func (ctx *passContext) httpHandlerTargets(call *ssa.Call) []*ssa.Function {
+	callee := call.Common().StaticCallee()
+	if callee == nil || !isNetHTTPFunc(callee) {
 		return nil
 	}
+	var targets []*ssa.Function
+	seen := make(map[ssa.Value]bool)
+	for _, arg := range call.Common().Args {
+		if isHTTPHandlerType(arg.Type()) {
+			targets = ctx.resolveHTTPHandler(arg, targets, seen)
+		}
+	}
+	return targets
+}
 
-	// Match net/http.HandleFunc (package-level) or (*ServeMux).HandleFunc
-	if !isHTTPHandleFunc(callee) {
+// httpServerHandlerTargets returns the handler stored in the Handler field of
+// an http.Server, as in &http.Server{Handler: h} or srv.Handler = h.
+func (ctx *passContext) httpServerHandlerTargets(store *ssa.Store) []*ssa.Function {
+	fa, ok := store.Addr.(*ssa.FieldAddr)
+	if !ok {
 		return nil
 	}
-
-	// The handler argument is the last argument.
-	args := common.Args
-	if len(args) == 0 {
+	ptr, ok := fa.X.Type().Underlying().(*types.Pointer)
+	if !ok || !isNetHTTPType(ptr.Elem(), "Server") {
 		return nil
 	}
-	handlerArg := args[len(args)-1]
+	st, ok := ptr.Elem().Underlying().(*types.Struct)
+	if !ok || st.Field(fa.Field).Name() != "Handler" {
+		return nil
+	}
+	return ctx.resolveHTTPHandler(store.Val, nil, make(map[ssa.Value]bool))
+}
 
-	// Direct function reference.
-	if fn, ok := handlerArg.(*ssa.Function); ok {
-		return fn
+// resolveHTTPHandler appends to targets the functions serving requests for
+// the handler value v:
+//   - functions and closures, possibly converted to http.HandlerFunc
+//   - the ServeHTTP method of a handler whose concrete type is known
+//   - the handlers returned by a middleware constructor, and the handlers it
+//     wraps, e.g. logging(http.HandlerFunc(index))
+func (ctx *passContext) resolveHTTPHandler(v ssa.Value, targets []*ssa.Function, seen map[ssa.Value]bool) []*ssa.Function {
+	if seen[v] {
+		return targets
 	}
-	// MakeClosure wrapping a function literal.
-	if mc, ok := handlerArg.(*ssa.MakeClosure); ok {
-		if fn, ok := mc.Fn.(*ssa.Function); ok {
-			return fn
+	seen[v] = true
+
+	switch v := v.(type) {
+	case *ssa.Function:
+		return append(targets, v)
+	case *ssa.MakeClosure:
+		if fn, ok := v.Fn.(*ssa.Function); ok {
+			return append(targets, fn)
+		}
+	case *ssa.ChangeType:
+		return ctx.resolveHTTPHandler(v.X, targets, seen)
+	case *ssa.MakeInterface:
+		if isNetHTTPType(v.X.Type(), "HandlerFunc") {
+			return ctx.resolveHTTPHandler(v.X, targets, seen)
+		}
+		if fn := ctx.serveHTTPMethod(v.X.Type()); fn != nil {
+			return append(targets, fn)
+		}
+		return ctx.resolveHTTPHandler(v.X, targets, seen)
+	case *ssa.Phi:
+		for _, edge := range v.Edges {
+			targets = ctx.resolveHTTPHandler(edge, targets, seen)
+		}
+	case *ssa.Call:
+		// Middleware: the handlers it wraps are called through their
+		// parameter, which the call graph does not see.
+		for _, arg := range v.Call.Args {
+			if isHTTPHandlerType(arg.Type()) {
+				targets = ctx.resolveHTTPHandler(arg, targets, seen)
+			}
+		}
+		callee := v.Call.StaticCallee()
+		if callee == nil {
+			return targets
+		}
+		for _, block := range callee.Blocks {
+			ret, ok := block.Instrs[len(block.Instrs)-1].(*ssa.Return)
+			if !ok {
+				continue
+			}
+			for _, res := range ret.Results {
+				if isHTTPHandlerType(res.Type()) {
+					targets = ctx.resolveHTTPHandler(res, targets, seen)
+				}
+			}
 		}
 	}
-	return nil
+	return targets
+}
+
+// stdCallbackArgs are the arguments of standard library functions whose
+// callback runs on another goroutine: timers, context cancellation hooks,
+// finalizers and cleanups.
+var stdCallbackArgs = []argPattern{
+	{pattern: "time.AfterFunc", index: 1},
+	{pattern: "context.AfterFunc", index: 1},
+	{pattern: "runtime.SetFinalizer", index: 1},
+	{pattern: "runtime.AddCleanup", index: 1},
+}
+
+// callbackTargets returns the callbacks of call that run on another
+// goroutine (see concurrentArgs).
+func (ctx *passContext) callbackTargets(call *ssa.Call) []*ssa.Function {
+	var targets []*ssa.Function
+	for _, arg := range ctx.concurrentArgs(call.Common()) {
+		targets = ctx.resolveCallback(arg, targets)
+	}
+	return targets
+}
+
+// concurrentArgs returns the arguments of a call that the callee runs on
+// another goroutine: the concurrent parameters of the callee, those of
+// stdCallbackArgs, the function passed to a Go method (see isGoMethod), and
+// those configured in entrypointArgs, e.g. the handler of
+// bus.Subscribe(topic, handler). Dynamic calls through an interface method
+// match the interface's patterns.
+func (ctx *passContext) concurrentArgs(common *ssa.CallCommon) []ssa.Value {
+	var concurrent []ssa.Value
+	var obj *types.Func
+	args := common.Args
+	if common.IsInvoke() {
+		obj = common.Method
+	} else if callee := common.StaticCallee(); callee != nil {
+		for _, i := range ctx.calleeConcurrentParams(callee) {
+			if i < len(args) {
+				concurrent = append(concurrent, args[i])
+			}
+		}
+		obj, _ = callee.Object().(*types.Func)
+		if callee.Signature.Recv() != nil {
+			args = args[1:]
+		}
+	}
+	if obj == nil {
+		return concurrent
+	}
+	indexes := argIndexes(stdCallbackArgs, obj)
+	indexes = append(indexes, argIndexes(ctx.configOf(ctx.pkgAt(common.Pos())).entrypointArgs, obj)...)
+	if isGoMethod(obj) {
+		indexes = append(indexes, 0)
+	}
+	for _, i := range indexes {
+		if i < len(args) {
+			concurrent = append(concurrent, args[i])
+		}
+	}
+	return concurrent
 }
 
-// isHTTPHandleFunc returns true if fn is net/http.HandleFunc or
-// (*net/http.ServeMux).HandleFunc.
-func isHTTPHandleFunc(fn *ssa.Function) bool {
-	if fn.Name() != "HandleFunc" {
+// resolveCallback appends to targets the functions run when the callback
+// value v is called: functions, closures and method values, or the methods of
+// the interface v is converted to, on its concrete type.
+func (ctx *passContext) resolveCallback(v ssa.Value, targets []*ssa.Function) []*ssa.Function {
+	switch v := v.(type) {
+	case *ssa.Function:
+		return append(targets, v)
+	case *ssa.MakeClosure:
+		fn, ok := v.Fn.(*ssa.Function)
+		if !ok {
+			return targets
+		}
+		// A method value s.handle is a closure over a synthetic wrapper
+		// calling the method.
+		if obj, ok := fn.Object().(*types.Func); ok && fn.Synthetic != "" {
+			if method := ctx.ssaPkg.Prog.FuncValue(obj); method != nil {
+				return append(targets, method)
+			}
+		}
+		return append(targets, fn)
+	case *ssa.ChangeType:
+		return ctx.resolveCallback(v.X, targets)
+	case *ssa.MakeInterface:
+		// A function passed as any, e.g. to runtime.SetFinalizer.
+		if _, ok := v.X.Type().Underlying().(*types.Signature); ok {
+			return ctx.resolveCallback(v.X, targets)
+		}
+		return append(targets, ctx.implementingMethods(v.X.Type(), v.Type())...)
+	}
+	return targets
+}
+
+// isGoMethod reports whether fn is a method named Go or TryGo taking a
+// single func() or func() error, the shape of (*sync.WaitGroup).Go,
+// (*errgroup.Group).Go and similar APIs running the function in a new
+// goroutine.
+func isGoMethod(fn *types.Func) bool {
+	if fn.Name() != "Go" && fn.Name() != "TryGo" {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Recv() == nil || sig.Params().Len() != 1 {
 		return false
 	}
-	pkg := fn.Package()
-	if pkg == nil || pkg.Pkg == nil {
+	callback, ok := sig.Params().At(0).Type().Underlying().(*types.Signature)
+	if !ok || callback.Params().Len() != 0 {
 		return false
 	}
-	return pkg.Pkg.Path() == "net/http"
+	switch callback.Results().Len() {
+	case 0:
+		return true
+	case 1:
+		return types.Identical(callback.Results().At(0).Type(), types.Universe.Lookup("error").Type())
+	}
+	return false
 }
 
-// isHTTPResponseWriter returns true if t is net/http.ResponseWriter.
-func isHTTPResponseWriter(t types.Type) bool {
-	// http.ResponseWriter is a named interface type.
-	named, ok := t.(*types.Named)
-	if !ok {
-		return false
+// configuredInterfaceTargets returns the methods of the package's types
+// implementing an interface configured in entrypointInterfaces.
+func (ctx *passContext) configuredInterfaceTargets() []*ssa.Function {
+	var targets []*ssa.Function
+	for _, pkg := range ctx.packages() {
+		ifaces := ctx.configOf(pkg).entrypointInterfaces(pkg)
+		if len(ifaces) == 0 {
+			continue
+		}
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() || types.IsInterface(tn.Type()) {
+				continue
+			}
+			ptr := types.NewPointer(tn.Type())
+			for _, iface := range ifaces {
+				if types.Implements(ptr, iface.Underlying().(*types.Interface)) {
+					targets = append(targets, ctx.implementingMethods(ptr, iface)...)
+				}
+			}
+		}
 	}
-	obj := named.Obj()
-	return obj.Pkg() != nil && obj.Pkg().Path() == "net/http" && obj.Name() == "ResponseWriter"
+	return targets
 }
 
-// isHTTPRequestPtr returns true if t is *net/http.Request.
-func isHTTPRequestPtr(t types.Type) bool {
-	ptr, ok := t.(*types.Pointer)
+// implementingMethods returns the methods of t implementing the methods of
+// the interface iface that are declared in the current package.
+func (ctx *passContext) implementingMethods(t, iface types.Type) []*ssa.Function {
+	it, ok := iface.Underlying().(*types.Interface)
 	if !ok {
+		return nil
+	}
+	prog := ctx.ssaPkg.Prog
+	mset := prog.MethodSets.MethodSet(t)
+	var methods []*ssa.Function
+	for i := 0; i < it.NumMethods(); i++ {
+		m := it.Method(i)
+		sel := mset.Lookup(m.Pkg(), m.Name())
+		if sel == nil {
+			continue
+		}
+		obj, ok := sel.Obj().(*types.Func)
+		if !ok || !ctx.isLocal(obj.Pkg()) {
+			continue
+		}
+		if fn := prog.FuncValue(obj); fn != nil {
+			methods = append(methods, fn)
+		}
+	}
+	return methods
+}
+
+// serveHTTPMethod returns the ServeHTTP method of t declared in the current
+// package, or nil.
+func (ctx *passContext) serveHTTPMethod(t types.Type) *ssa.Function {
+	prog := ctx.ssaPkg.Prog
+	sel := prog.MethodSets.MethodSet(t).Lookup(nil, "ServeHTTP")
+	if sel == nil {
+		return nil
+	}
+	obj, ok := sel.Obj().(*types.Func)
+	if !ok || !ctx.isLocal(obj.Pkg()) {
+		return nil
+	}
+	fn := prog.FuncValue(obj)
+	if fn == nil || !isServeHTTPMethod(fn) {
+		return nil
+	}
+	return fn
+}
+
+// isNetHTTPFunc returns true if fn is a function or method of net/http.
+func isNetHTTPFunc(fn *ssa.Function) bool {
+	obj, ok := fn.Object().(*types.Func)
+	return ok && obj.Pkg() != nil && obj.Pkg().Path() == "net/http"
+}
+
+// isHTTPHandlerType returns true if t is http.Handler, http.HandlerFunc or
+// func(http.ResponseWriter, *http.Request).
+func isHTTPHandlerType(t types.Type) bool {
+	if isNetHTTPType(t, "Handler") || isNetHTTPType(t, "HandlerFunc") {
+		return true
+	}
+	sig, ok := t.(*types.Signature)
+	if !ok || sig.Params().Len() != 2 || sig.Results().Len() != 0 {
 		return false
 	}
-	named, ok := ptr.Elem().(*types.Named)
+	return isHTTPResponseWriter(sig.Params().At(0).Type()) && isHTTPRequestPtr(sig.Params().At(1).Type())
+}
+
+// isNetHTTPType returns true if t is the named type net/http.<name>.
+func isNetHTTPType(t types.Type, name string) bool {
+	named, ok := t.(*types.Named)
 	if !ok {
 		return false
 	}
 	obj := named.Obj()
-	return obj.Pkg() != nil && obj.Pkg().Path() == "net/http" && obj.Name() == "Request"
+	return obj.Pkg() != nil && obj.Pkg().Path() == "net/http" && obj.Name() == name
+}
+
+// isHTTPResponseWriter returns true if t is net/http.ResponseWriter.
+func isHTTPResponseWriter(t types.Type) bool {
+	return isNetHTTPType(t, "ResponseWriter")
+}
+
+// isHTTPRequestPtr returns true if t is *net/http.Request.
+func isHTTPRequestPtr(t types.Type) bool {
+	ptr, ok := t.(*types.Pointer)
+	return ok && isNetHTTPType(ptr.Elem(), "Request")
 }
@@ -0,0 +1,193 @@
+package analyzer
+
+import (
+	"go/token"
+	"sort"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// Functions running a function-typed parameter on another goroutine make the
+// callbacks their callers pass concurrent entrypoints:
+//
+//	func (p *Pool) Submit(task func()) { go task() }
+//
+// A parameter runs concurrently when the function launches it with a go
+// statement, passes it to an argument running concurrently (time.AfterFunc,
+// another such function, ...) or invokes it from a concurrent context, also
+// from a closure capturing it. ConcurrentFact exports the parameters, so that
+// callers in importing packages get the concurrency context of their
+// callbacks from the function's package.
+
+// collectConcurrentParams records the concurrent parameters of the package's
+// functions until a fixed point, and reports whether any was added. Callers
+// of a function with concurrent parameters may pass their own parameters.
+func (ctx *passContext) collectConcurrentParams() bool {
+	added := false
+	for changed := true; changed; {
+		changed = false
+		for _, fn := range ctx.srcFuncs {
+			for _, block := range fn.Blocks {
+				for _, instr := range block.Instrs {
+					for _, v := range ctx.concurrentValues(fn, instr) {
+						if ctx.markConcurrentParam(v) {
+							changed = true
+							added = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return added
+}
+
+// concurrentValues returns the function values instr runs concurrently: the
+// function launched by a go statement, the concurrent arguments of a call,
+// and the function called dynamically by a function in a concurrent context.
+func (ctx *passContext) concurrentValues(fn *ssa.Function, instr ssa.Instruction) []ssa.Value {
+	switch instr := instr.(type) {
+	case *ssa.Go:
+		common := instr.Common()
+		values := ctx.concurrentArgs(common)
+		if !common.IsInvoke() && common.StaticCallee() == nil {
+			values = append(values, common.Value)
+		}
+		return values
+	case *ssa.Call:
+		common := instr.Common()
+		values := ctx.concurrentArgs(common)
+		// Without detected entrypoints, every function is concurrent: that
+		// fallback says nothing about the parameter.
+		if ctx.concurrentFuncs[fn] && !common.IsInvoke() && common.StaticCallee() == nil {
+			values = append(values, common.Value)
+		}
+		return values
+	}
+	return nil
+}
+
+// markConcurrentParam marks v as a concurrent parameter if it is one of a
+// top-level function, and reports whether it was not already marked.
+func (ctx *passContext) markConcurrentParam(v ssa.Value) bool {
+	fn, idx, ok := ctx.paramOf(v)
+	if !ok {
+		return false
+	}
+	params := ctx.concurrentParams[fn]
+	if params == nil {
+		params = make(map[int]bool)
+		ctx.concurrentParams[fn] = params
+	}
+	if params[idx] {
+		return false
+	}
+	params[idx] = true
+	return true
+}
+
+// paramOf returns the top-level function and the index (including the
+// receiver) of the parameter v, following the variables captured by closures
+// to the parameter of the enclosing function. A captured parameter is a load
+// of a free variable bound to the cell the parameter is stored in.
+func (ctx *passContext) paramOf(v ssa.Value) (*ssa.Function, int, bool) {
+	for {
+		switch p := v.(type) {
+		case *ssa.Parameter:
+			fn := p.Parent()
+			if fn.Parent() != nil {
+				return nil, -1, false // parameter of a closure
+			}
+			for i, q := range fn.Params {
+				if q == p {
+					return fn, i, true
+				}
+			}
+			return nil, -1, false
+		case *ssa.FreeVar:
+			closure := p.Parent()
+			mc := ctx.closureSite(closure)
+			if mc == nil {
+				return nil, -1, false
+			}
+			next := ssa.Value(nil)
+			for i, fv := range closure.FreeVars {
+				if fv == p {
+					next = mc.Bindings[i]
+				}
+			}
+			if next == nil {
+				return nil, -1, false
+			}
+			v = next
+		case *ssa.UnOp:
+			if p.Op != token.MUL {
+				return nil, -1, false
+			}
+			v = p.X
+		case *ssa.Alloc:
+			var stored ssa.Value
+			for _, ref := range *p.Referrers() {
+				if st, ok := ref.(*ssa.Store); ok && st.Addr == p {
+					if stored != nil {
+						return nil, -1, false // reassigned
+					}
+					stored = st.Val
+				}
+			}
+			if stored == nil {
+				return nil, -1, false
+			}
+			v = stored
+		case *ssa.ChangeType:
+			v = p.X
+		default:
+			return nil, -1, false
+		}
+	}
+}
+
+// closureSite returns the MakeClosure instruction creating closure.
+func (ctx *passContext) closureSite(closure *ssa.Function) *ssa.MakeClosure {
+	if ctx.closureSites == nil {
+		ctx.closureSites = make(map[*ssa.Function]*ssa.MakeClosure)
+		for _, fn := range ctx.srcFuncs {
+			for _, block := range fn.Blocks {
+				for _, instr := range block.Instrs {
+					if mc, ok := instr.(*ssa.MakeClosure); ok {
+						if target, ok := mc.Fn.(*ssa.Function); ok {
+							ctx.closureSites[target] = mc
+						}
+					}
+				}
+			}
+		}
+	}
+	return ctx.closureSites[closure]
+}
+
+// calleeConcurrentParams returns the concurrent parameters of callee, from
+// this package or from its ConcurrentFact.
+func (ctx *passContext) calleeConcurrentParams(callee *ssa.Function) []int {
+	if params, ok := ctx.concurrentParams[callee]; ok {
+		return sortedParams(params)
+	}
+	if len(ctx.pass.Analyzer.FactTypes) == 0 || callee.Object() == nil || ctx.isLocal(callee.Object().Pkg()) {
+		return nil
+	}
+	var fact ConcurrentFact
+	if !ctx.pass.ImportObjectFact(callee.Object(), &fact) {
+		return nil
+	}
+	return fact.Params
+}
+
+// sortedParams returns the parameter indexes of params in increasing order.
+func sortedParams(params map[int]bool) []int {
+	indexes := make([]int, 0, len(params))
+	for i := range params {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
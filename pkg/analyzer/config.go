@@ -0,0 +1,425 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// configFileNames are the project configuration files looked up, in order, in
+// the package directory and its parents up to the module root.
+var configFileNames = []string{"golintmu.json", ".golintmu.json"}
+
+// config is the project configuration file. Every setting is optional:
+//
+//	{
+//	  "checks": ["-C13", "-C14"],
+//	  "severity": {"C9": "note"},
+//	  "constructorPrefixes": ["Build", "Open"],
+//	  "entrypoints": ["example.com/app/jobs.Run*", "example.com/app.Worker.Handle*"],
+//	  "entrypointArgs": ["example.com/app/bus.Bus.Subscribe:1", "example.com/app/cron.Every:1"],
+//	  "entrypointInterfaces": ["example.com/app/mq.Consumer"],
+//	  "mutexTypes": ["example.com/app/internal/spin.Lock"],
+//	  "exclude": {
+//	    "packages": ["example.com/app/generated/..."],
+//	    "types": ["example.com/app.Metrics"],
+//	    "fields": ["example.com/app.Server.debug"]
+//	  }
+//	}
+//
+// checks and severity use the syntax of the -checks and -severity flags,
+// which take precedence when set.
+type config struct {
+	Checks               []string          `json:"checks"`
+	Severity             map[string]string `json:"severity"`
+	ConstructorPrefixes  []string          `json:"constructorPrefixes"`  // in addition to New, Make and Create
+	Entrypoints          []string          `json:"entrypoints"`          // concurrent entrypoints, "pkgpath.Func" or "pkgpath.Type.Method"; the name may contain path.Match wildcards
+	EntrypointArgs       []string          `json:"entrypointArgs"`       // callbacks run concurrently by the function or method they are passed to, "pkgpath.Func:N" or "pkgpath.Type.Method:N" (N is the argument index, from 0, receiver excluded)
+	EntrypointInterfaces []string          `json:"entrypointInterfaces"` // "pkgpath.Interface": the methods of the package's types implementing it are concurrent entrypoints
+	MutexTypes           []string          `json:"mutexTypes"`           // types with Lock/Unlock (and RLock/RUnlock) methods treated like sync.Mutex, "pkgpath.Type"
+	Exclude              struct {
+		Packages []string `json:"packages"` // import paths, "/..." matches subpackages
+		Types    []string `json:"types"`    // "pkgpath.Type": no guard is inferred for its fields
+		Fields   []string `json:"fields"`   // "pkgpath.Type.field"
+	} `json:"exclude"`
+
+	checks         checkSelection
+	severities     severityMap
+	entrypointArgs []argPattern    // parsed EntrypointArgs
+	mutexTypes     map[string]bool // set of MutexTypes
+	path           string          // file the configuration was read from
+}
+
+// configCacheKey identifies a version of a configuration file. A file
+// created, edited or removed since it was parsed gets a new key, so that
+// long-running drivers (gopls, test binaries) never use a stale parse.
+type configCacheKey struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// configCacheEntry is the result of parsing a configuration file.
+type configCacheEntry struct {
+	cfg *config
+	err error
+}
+
+// configCache caches parsed configuration files: packages of a module share
+// their configuration file. The lookup itself is not cached.
+var configCache sync.Map // configCacheKey → configCacheEntry
+
+// packageConfig returns the configuration applying to the package of pass,
+// looked up from the directory of its first file.
+func packageConfig(pass *analysis.Pass) (*config, error) {
+	return filesConfig(pass.Fset, pass.Files)
+}
+
+// filesConfig returns the configuration applying to the package made of
+// files, looked up from the directory of the first one.
+func filesConfig(fset *token.FileSet, files []*ast.File) (*config, error) {
+	if len(files) == 0 {
+		return &config{}, nil
+	}
+	tf := fset.File(files[0].Pos())
+	if tf == nil {
+		return &config{}, nil
+	}
+	return findConfig(filepath.Dir(tf.Name()))
+}
+
+// findConfig walks up from dir to the module root (the directory containing
+// go.mod) or the filesystem root, and parses the first configuration file. A
+// directory without one gets an empty configuration.
+func findConfig(dir string) (*config, error) {
+	for {
+		for _, name := range configFileNames {
+			file := filepath.Join(dir, name)
+			info, err := os.Stat(file)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			return loadConfig(file, info)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return &config{}, nil
+}
+
+// loadConfig returns the parsed configuration file, from the cache when the
+// file has not changed since it was last parsed.
+func loadConfig(file string, info os.FileInfo) (*config, error) {
+	key := configCacheKey{path: file, modTime: info.ModTime(), size: info.Size()}
+	if v, ok := configCache.Load(key); ok {
+		e := v.(configCacheEntry)
+		return e.cfg, e.err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := parseConfig(file, data)
+	configCache.Store(key, configCacheEntry{cfg, err})
+	return cfg, err
+}
+
+// parseConfig decodes and validates a configuration file. Unknown settings
+// are rejected so that typos do not go unnoticed.
+func parseConfig(file string, data []byte) (*config, error) {
+	cfg := &config{path: file}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	if err := cfg.checks.Set(strings.Join(cfg.Checks, ",")); err != nil {
+		return nil, fmt.Errorf("%s: checks: %v", file, err)
+	}
+	var sev []string
+	for id, s := range cfg.Severity {
+		sev = append(sev, id+"="+s)
+	}
+	if err := cfg.severities.Set(strings.Join(sev, ",")); err != nil {
+		return nil, fmt.Errorf("%s: severity: %v", file, err)
+	}
+	for _, p := range cfg.Entrypoints {
+		if !isQualifiedName(p) {
+			return nil, fmt.Errorf("%s: entrypoints: %q is not of the form pkgpath.Func or pkgpath.Type.Method", file, p)
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("%s: entrypoints: %q: %v", file, p, err)
+		}
+	}
+	for _, a := range cfg.EntrypointArgs {
+		p, err := parseArgPattern(a)
+		if err != nil {
+			return nil, fmt.Errorf("%s: entrypointArgs: %v", file, err)
+		}
+		cfg.entrypointArgs = append(cfg.entrypointArgs, p)
+	}
+	for _, i := range cfg.EntrypointInterfaces {
+		if !isQualifiedName(i) {
+			return nil, fmt.Errorf("%s: entrypointInterfaces: %q is not of the form pkgpath.Interface", file, i)
+		}
+	}
+	for _, t := range cfg.MutexTypes {
+		if !isQualifiedName(t) {
+			return nil, fmt.Errorf("%s: mutexTypes: %q is not of the form pkgpath.Type", file, t)
+		}
+		if cfg.mutexTypes == nil {
+			cfg.mutexTypes = make(map[string]bool)
+		}
+		cfg.mutexTypes[t] = true
+	}
+	return cfg, nil
+}
+
+// isQualifiedName reports whether s has a package path followed by a name,
+// e.g. "example.com/app.Worker".
+func isQualifiedName(s string) bool {
+	last := s[strings.LastIndex(s, "/")+1:]
+	dot := strings.Index(last, ".")
+	return dot > 0 && dot < len(last)-1
+}
+
+// effectiveChecks returns the check selection: the -checks flag when set,
+// the configuration otherwise.
+func (cfg *config) effectiveChecks(flag checkSelection) checkSelection {
+	if flag.raw != "" {
+		return flag
+	}
+	return cfg.checks
+}
+
+// effectiveSeverities merges the severities of the configuration with the
+// -severity flag, which takes precedence.
+func (cfg *config) effectiveSeverities(flag severityMap) severityMap {
+	if len(cfg.severities) == 0 {
+		return flag
+	}
+	merged := severityMap{}
+	for id, sev := range cfg.severities {
+		merged[id] = sev
+	}
+	for id, sev := range flag {
+		merged[id] = sev
+	}
+	return merged
+}
+
+// packageExcluded reports whether the package with import path pkgPath is
+// excluded from reporting.
+func (cfg *config) packageExcluded(pkgPath string) bool {
+	for _, p := range cfg.Exclude.Packages {
+		if prefix, ok := strings.CutSuffix(p, "/..."); ok {
+			if pkgPath == prefix || strings.HasPrefix(pkgPath, prefix+"/") {
+				return true
+			}
+		} else if pkgPath == p {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldExcluded reports whether no guard should be inferred for key, because
+// its struct type or the field itself is excluded.
+func (cfg *config) fieldExcluded(key fieldKey) bool {
+	if len(cfg.Exclude.Types) == 0 && len(cfg.Exclude.Fields) == 0 {
+		return false
+	}
+	typeName := qualifiedTypeName(key.StructType)
+	for _, t := range cfg.Exclude.Types {
+		if t == typeName {
+			return true
+		}
+	}
+	st, ok := key.StructType.Underlying().(*types.Struct)
+	if !ok || key.FieldIndex >= st.NumFields() {
+		return false
+	}
+	fieldName := typeName + "." + st.Field(key.FieldIndex).Name()
+	for _, f := range cfg.Exclude.Fields {
+		if f == fieldName {
+			return true
+		}
+	}
+	return false
+}
+
+// isEntrypoint reports whether fn matches a configured entrypoint pattern.
+func (cfg *config) isEntrypoint(fn *ssa.Function) bool {
+	if len(cfg.Entrypoints) == 0 || fn.Parent() != nil {
+		return false
+	}
+	obj, ok := fn.Object().(*types.Func)
+	return ok && matchesFuncPattern(cfg.Entrypoints, obj)
+}
+
+// argPattern is an entrypointArgs setting: argument index of the functions
+// matching pattern.
+type argPattern struct {
+	pattern string
+	index   int
+}
+
+// parseArgPattern parses "pkgpath.Func:N" or "pkgpath.Type.Method:N".
+func parseArgPattern(s string) (argPattern, error) {
+	name, index, ok := strings.Cut(s, ":")
+	if !ok || !isQualifiedName(name) {
+		return argPattern{}, fmt.Errorf("%q is not of the form pkgpath.Func:N or pkgpath.Type.Method:N", s)
+	}
+	n, err := strconv.Atoi(index)
+	if err != nil || n < 0 {
+		return argPattern{}, fmt.Errorf("%q: argument index %q is not a non-negative integer", s, index)
+	}
+	if _, err := path.Match(name, ""); err != nil {
+		return argPattern{}, fmt.Errorf("%q: %v", s, err)
+	}
+	return argPattern{pattern: name, index: n}, nil
+}
+
+// argIndexes returns the indexes of the arguments of fn, receiver excluded,
+// matched by patterns.
+func argIndexes(patterns []argPattern, fn *types.Func) []int {
+	var indexes []int
+	for _, a := range patterns {
+		if matchesFuncPattern([]string{a.pattern}, fn) {
+			indexes = append(indexes, a.index)
+		}
+	}
+	return indexes
+}
+
+// matchesFuncPattern reports whether fn matches one of patterns,
+// "pkgpath.Func" or "pkgpath.Type.Method", whose name may contain path.Match
+// wildcards.
+func matchesFuncPattern(patterns []string, fn *types.Func) bool {
+	if fn.Pkg() == nil {
+		return false
+	}
+	name := fn.Name()
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		named := receiverNamed(recv.Type())
+		if named == nil {
+			return false
+		}
+		name = named.Obj().Name() + "." + name
+	}
+	prefix := fn.Pkg().Path() + "."
+	for _, p := range patterns {
+		pattern, ok := strings.CutPrefix(p, prefix)
+		if !ok {
+			continue
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// entrypointInterfaces returns the configured entrypoint interfaces declared
+// in pkg or its transitive imports.
+func (cfg *config) entrypointInterfaces(pkg *types.Package) []*types.Named {
+	if len(cfg.EntrypointInterfaces) == 0 {
+		return nil
+	}
+	pkgs := map[string]*types.Package{}
+	queue := []*types.Package{pkg}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if pkgs[p.Path()] != nil {
+			continue
+		}
+		pkgs[p.Path()] = p
+		queue = append(queue, p.Imports()...)
+	}
+	var ifaces []*types.Named
+	for _, name := range cfg.EntrypointInterfaces {
+		dot := strings.LastIndex(name, ".")
+		p := pkgs[name[:dot]]
+		if p == nil {
+			continue
+		}
+		tn, ok := p.Scope().Lookup(name[dot+1:]).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		if named, ok := tn.Type().(*types.Named); ok && types.IsInterface(named) {
+			ifaces = append(ifaces, named)
+		}
+	}
+	return ifaces
+}
+
+// receiverNamed returns the named type of a (pointer) receiver.
+func receiverNamed(t types.Type) *types.Named {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, _ := t.(*types.Named)
+	return named
+}
+
+// isCustomMutexType reports whether named is a configured mutex type.
+func (cfg *config) isCustomMutexType(named *types.Named) bool {
+	return cfg.mutexTypes[qualifiedTypeName(named)]
+}
+
+// isCustomMutexType reports whether named is a mutex type of the
+// configuration. In whole-program mode, the analyzed packages form one
+// program: a type configured by any of them is a mutex.
+func (ctx *passContext) isCustomMutexType(named *types.Named) bool {
+	if ctx.config.isCustomMutexType(named) {
+		return true
+	}
+	for _, cfg := range ctx.configs {
+		if cfg.isCustomMutexType(named) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCustomMutexMethod reports whether fn is a lock method of a configured
+// mutex type. Its body implements the lock and is not analyzed.
+func (ctx *passContext) isCustomMutexMethod(fn *ssa.Function) bool {
+	recv := fn.Signature.Recv()
+	if recv == nil || !isLockMethod(fn.Name()) {
+		return false
+	}
+	named := receiverNamed(recv.Type())
+	return named != nil && ctx.isCustomMutexType(named)
+}
+
+// fieldExcluded reports whether key is excluded by the configuration of the
+// package declaring its struct.
+func (ctx *passContext) fieldExcluded(key fieldKey) bool {
+	return ctx.configOf(key.StructType.Obj().Pkg()).fieldExcluded(key)
+}
@@ -0,0 +1,381 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// Function lock contracts declare, in a function's doc comment, the locks its
+// callers must hold (//mu:requires), the locks it returns holding
+// (//mu:acquires) and the caller's locks it releases (//mu:releases):
+//
+//	//mu:requires s.mu
+//	func (s *Server) evictLocked() { ... }
+//
+// A contract replaces inference for the function: its facts are exactly the
+// declared ones, and its body is walked with the required locks held. The
+// body is checked against the contract at every return (C15).
+
+// contractKind is the directive declaring a contract lock.
+type contractKind int
+
+const (
+	contractRequires contractKind = iota // //mu:requires: held on entry and on return
+	contractAcquires                     // //mu:acquires: not held on entry, held on return
+	contractReleases                     // //mu:releases: held on entry, not held on return
+)
+
+// contractLock is a mutex named by a contract directive.
+type contractLock struct {
+	kind contractKind
+	ref  lockRef       // the mutex in the function's terms
+	mfk  mutexFieldKey // the mutex in type-scoped terms
+	expr string        // the mutex as written, e.g. "s.mu"
+	pos  token.Pos     // position of the directive
+}
+
+// lockContract is the contract of a function.
+type lockContract struct {
+	locks []contractLock
+}
+
+// heldOnEntry reports whether the contract lock is held when the function is
+// called.
+func (l contractLock) heldOnEntry() bool {
+	return l.kind != contractAcquires
+}
+
+// heldOnReturn reports whether the contract lock must be held when the
+// function returns.
+func (l contractLock) heldOnReturn() bool {
+	return l.kind != contractReleases
+}
+
+// heldOnReturn reports whether ref is a lock the contract holds on return,
+// which is therefore not leaked. It is false for a nil contract.
+func (c *lockContract) heldOnReturn(ref lockRef) bool {
+	if c == nil {
+		return false
+	}
+	for _, l := range c.locks {
+		if l.ref == ref && l.heldOnReturn() {
+			return true
+		}
+	}
+	return false
+}
+
+// contract returns the lock contract of fn, or nil.
+func (ctx *passContext) contract(fn *ssa.Function) *lockContract {
+	if ctx.annotations == nil {
+		return nil
+	}
+	return ctx.annotations.contracts[fn]
+}
+
+// parseContract resolves the mutexes of a //mu:requires, //mu:acquires or
+// //mu:releases directive, which must be in the doc comment of a function.
+// The mutexes are a comma-separated list of parameter (or receiver) fields,
+// "s.mu", or package-level mutexes, "mu", optionally followed by a reason.
+func (ctx *passContext) parseContract(ann *annotations, funcDecls []*ast.FuncDecl, comment *ast.Comment, d directive) {
+	var fd *ast.FuncDecl
+	for _, decl := range funcDecls {
+		if decl.Doc != nil && comment.Pos() >= decl.Doc.Pos() && comment.End() <= decl.Doc.End() {
+			fd = decl
+			break
+		}
+	}
+	if fd == nil {
+		ctx.reportDirective(comment.Pos(), "//%s directive must be in the doc comment of a function", d.text)
+		return
+	}
+	if d.checks != nil {
+		ctx.reportDirective(comment.Pos(), "//%s directive takes no check list", d.text)
+		return
+	}
+	fn := ctx.astFuncToSSA(fd)
+	if fn == nil {
+		return
+	}
+
+	kind := contractRequires
+	switch d.name {
+	case "acquires":
+		kind = contractAcquires
+	case "releases":
+		kind = contractReleases
+	}
+
+	list, _, _ := strings.Cut(d.args, "//")
+	var exprs []string
+	for _, expr := range strings.Split(list, ",") {
+		if expr = strings.TrimSpace(expr); expr != "" {
+			exprs = append(exprs, expr)
+		}
+	}
+	if len(exprs) == 0 {
+		ctx.reportDirective(comment.Pos(), "//%s directive names no mutex, e.g. //%s s.mu", d.text, d.text)
+		return
+	}
+
+	for _, expr := range exprs {
+		ref, mfk, ok := ctx.resolveContractLock(fn, expr)
+		if !ok {
+			ctx.reportDirective(comment.Pos(), "//%s directive: %s is not a mutex field of a pointer parameter or a package-level mutex", d.text, expr)
+			continue
+		}
+		c := ann.contracts[fn]
+		if c == nil {
+			c = &lockContract{}
+			ann.contracts[fn] = c
+		}
+		c.locks = append(c.locks, contractLock{kind: kind, ref: ref, mfk: mfk, expr: expr, pos: comment.Pos()})
+	}
+}
+
+// resolveContractLock resolves a contract mutex expression of fn: "p.field",
+// a mutex field of the pointer parameter or receiver p, or "v", a
+// package-level mutex of fn's package.
+func (ctx *passContext) resolveContractLock(fn *ssa.Function, expr string) (lockRef, mutexFieldKey, bool) {
+	name, fieldName, isField := strings.Cut(expr, ".")
+	if !isField {
+		v, ok := ctx.pkgOf(fn).Scope().Lookup(name).(*types.Var)
+		if !ok || !ctx.isMutexType(v.Type()) {
+			return lockRef{}, mutexFieldKey{}, false
+		}
+		g := ctx.ssaGlobal(v)
+		if g == nil {
+			return lockRef{}, mutexFieldKey{}, false
+		}
+		return lockRef{kind: globalLock, base: g, fieldIndex: -1}, mutexFieldKey{Global: v}, true
+	}
+
+	for _, p := range fn.Params {
+		if p.Name() != name {
+			continue
+		}
+		if _, ok := p.Type().Underlying().(*types.Pointer); !ok {
+			return lockRef{}, mutexFieldKey{}, false
+		}
+		named := receiverNamed(p.Type())
+		if named == nil {
+			return lockRef{}, mutexFieldKey{}, false
+		}
+		st, ok := named.Underlying().(*types.Struct)
+		if !ok {
+			return lockRef{}, mutexFieldKey{}, false
+		}
+		for i := 0; i < st.NumFields(); i++ {
+			field := st.Field(i)
+			if field.Name() != fieldName || !ctx.isMutexType(field.Type()) {
+				continue
+			}
+			ref := lockRef{kind: fieldLock, base: canonicalizeBase(p), fieldIndex: i}
+			return ref, mutexFieldKey{StructType: named, FieldIndex: i}, true
+		}
+		return lockRef{}, mutexFieldKey{}, false
+	}
+	return lockRef{}, mutexFieldKey{}, false
+}
+
+// seedContractFacts sets the lock facts of functions with a contract: the
+// required and released locks are requirements, the acquired locks are
+// acquisitions held on return. Inference does not add to them.
+func (ctx *passContext) seedContractFacts() {
+	for fn, c := range ctx.annotations.contracts {
+		facts := ctx.getOrCreateFuncFacts(fn)
+		for _, l := range c.locks {
+			switch l.kind {
+			case contractRequires:
+				facts.Requires[l.mfk] = true
+			case contractAcquires:
+				facts.Acquires[l.mfk] = true
+				facts.ReturnsHolding[l.mfk] = true
+			case contractReleases:
+				facts.Requires[l.mfk] = true
+				facts.ReleasesHeld[l.mfk] = true
+			}
+		}
+	}
+}
+
+// seedContractLocks marks the locks fn's contract holds on entry as held.
+func (ctx *passContext) seedContractLocks(fn *ssa.Function, ls *lockState) {
+	c := ctx.contract(fn)
+	if c == nil {
+		return
+	}
+	for _, l := range c.locks {
+		if l.heldOnEntry() {
+			ls.lock(l.ref, true, fn.Pos())
+		}
+	}
+}
+
+// releaseCalleeLocks releases the locks held by fn that callee releases for
+// its caller (//mu:releases), as if fn unlocked them at the call. Only locks
+// of the call's arguments (or package-level mutexes) are released.
+func (ctx *passContext) releaseCalleeLocks(fn, callee *ssa.Function, args []ssa.Value, ls *lockState) {
+	facts := ctx.calleeLockFacts(callee)
+	if facts == nil || len(facts.ReleasesHeld) == 0 {
+		return
+	}
+	for ref := range ls.held {
+		mfk, ok := lockRefToMutexFieldKey(&ref)
+		if !ok || !facts.ReleasesHeld[mfk] {
+			continue
+		}
+		if ref.kind == fieldLock && !isCallArgument(ref.base, args) {
+			continue
+		}
+		ls.unlock(ref)
+		ctx.getOrCreateFuncFacts(fn).Releases[mfk] = true
+	}
+}
+
+// acquireCalleeLocks marks the locks callee returns holding (//mu:acquires) as
+// held by fn after the call, as if fn locked them at the call. The contract of
+// a callee of the package binds each lock to the argument passed for its
+// parameter; the facts of an imported callee to the only argument of the
+// mutex's struct type.
+func (ctx *passContext) acquireCalleeLocks(callee *ssa.Function, args []ssa.Value, pos token.Pos, ls *lockState) {
+	if c := ctx.contract(callee); c != nil {
+		for _, l := range c.locks {
+			if l.kind != contractAcquires {
+				continue
+			}
+			if ref, ok := bindContractLock(callee, l.ref, args); ok {
+				ls.lock(ref, true, pos)
+			}
+		}
+		return
+	}
+	facts := ctx.calleeLockFacts(callee)
+	if facts == nil {
+		return
+	}
+	for mfk := range facts.ReturnsHolding {
+		if mfk.Global != nil {
+			if g := ctx.ssaGlobal(mfk.Global); g != nil {
+				ls.lock(lockRef{kind: globalLock, base: g, fieldIndex: -1}, true, pos)
+			}
+			continue
+		}
+		var base ssa.Value
+		for _, arg := range args {
+			if receiverNamed(arg.Type()) != mfk.StructType {
+				continue
+			}
+			if base != nil {
+				base = nil // ambiguous
+				break
+			}
+			base = canonicalizeBase(arg)
+		}
+		if base != nil {
+			ls.lock(lockRef{kind: fieldLock, base: base, fieldIndex: mfk.FieldIndex}, true, pos)
+		}
+	}
+}
+
+// bindContractLock returns ref, a contract lock of callee, in the terms of a
+// caller passing args.
+func bindContractLock(callee *ssa.Function, ref lockRef, args []ssa.Value) (lockRef, bool) {
+	if ref.kind == globalLock {
+		return ref, true
+	}
+	for i, p := range callee.Params {
+		if canonicalizeBase(p) == ref.base && i < len(args) {
+			return lockRef{kind: fieldLock, base: canonicalizeBase(args[i]), fieldIndex: ref.fieldIndex}, true
+		}
+	}
+	return lockRef{}, false
+}
+
+// isCallArgument reports whether base is the canonical value of one of args.
+func isCallArgument(base ssa.Value, args []ssa.Value) bool {
+	for _, arg := range args {
+		if canonicalizeBase(arg) == base {
+			return true
+		}
+	}
+	return false
+}
+
+// contractViolation is a return of a function whose lock state contradicts a
+// lock of its contract (C15).
+type contractViolation struct {
+	Fn   *ssa.Function
+	Pos  token.Pos
+	Lock contractLock
+}
+
+// checkContractAtReturn records a C15 candidate for each contract lock of fn
+// whose state at a return contradicts the contract. A lock with a pending
+// deferred unlock counts as released. Uses a map keyed by the return position
+// to clear stale candidates on block re-walks.
+func (ctx *passContext) checkContractAtReturn(fn *ssa.Function, ret *ssa.Return, ls *lockState) {
+	c := ctx.contract(fn)
+	if c == nil {
+		return
+	}
+	pos := ret.Pos()
+	if !pos.IsValid() {
+		pos = functionEnd(fn)
+	}
+	delete(ctx.contractViolations, pos)
+	for _, l := range c.locks {
+		_, held := ls.held[l.ref]
+		held = held && !ls.deferredUnlocks[l.ref]
+		if held != l.heldOnReturn() {
+			ctx.contractViolations[pos] = append(ctx.contractViolations[pos], contractViolation{Fn: fn, Pos: pos, Lock: l})
+		}
+	}
+}
+
+// functionEnd returns the position of the closing brace of fn's body, the
+// position of its implicit return.
+func functionEnd(fn *ssa.Function) token.Pos {
+	if fd, ok := fn.Syntax().(*ast.FuncDecl); ok && fd.Body != nil {
+		return fd.Body.Rbrace
+	}
+	return fn.Pos()
+}
+
+// reportContractViolations reports the C15 candidates collected during the
+// SSA walk.
+func (ctx *passContext) reportContractViolations() {
+	for _, violations := range ctx.contractViolations {
+		for _, v := range violations {
+			ctx.reportContractViolation(v)
+		}
+	}
+}
+
+// reportContractViolation emits a C15 diagnostic for a return contradicting
+// the function's lock contract.
+func (ctx *passContext) reportContractViolation(v contractViolation) {
+	if ctx.isSuppressed(v.Fn, v.Pos, "C15") {
+		return
+	}
+	var msg string
+	switch v.Lock.kind {
+	case contractRequires:
+		msg = fmt.Sprintf("%s() returns without holding %s despite //mu:requires %s \u2014 use //mu:releases if it releases the caller's lock",
+			v.Fn.Name(), v.Lock.expr, v.Lock.expr)
+	case contractAcquires:
+		msg = fmt.Sprintf("%s() returns without holding %s despite //mu:acquires %s", v.Fn.Name(), v.Lock.expr, v.Lock.expr)
+	case contractReleases:
+		msg = fmt.Sprintf("%s() returns holding %s despite //mu:releases %s", v.Fn.Name(), v.Lock.expr, v.Lock.expr)
+	}
+	ctx.report(v.Pos, withMutex(Finding{
+		Check:   "C15",
+		Message: msg,
+		Func:    ctx.funcName(v.Fn),
+	}, v.Lock.mfk), relatedAt(v.Lock.pos, "contract declared here"))
+}
@@ -0,0 +1,177 @@
+package analyzer
+
+import (
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// mutexCopyKey deduplicates C10 diagnostics by copy site and mutex field.
+type mutexCopyKey struct {
+	pos        token.Pos
+	mutexField mutexFieldKey
+}
+
+// checkMutexCopies scans source functions for subtle copies of structs that
+// contain a mutex, which go vet's copylocks does not tie back to guarded state:
+//   - a copied value (value receiver, by-value parameter, range variable) whose
+//     mutex is then locked
+//   - a struct returned by value as a copy of an existing instance
+//   - a struct copied into an interface
+//
+// Runs after guard inference so diagnostics can name the fields the original
+// mutex guards.
+func (ctx *passContext) checkMutexCopies() {
+	reported := make(map[mutexCopyKey]bool)
+	report := func(fn *ssa.Function, pos token.Pos, mfk mutexFieldKey) {
+		if !pos.IsValid() {
+			return
+		}
+		key := mutexCopyKey{pos: pos, mutexField: mfk}
+		if reported[key] {
+			return
+		}
+		reported[key] = true
+		ctx.reportMutexCopy(fn, pos, mfk)
+	}
+
+	for _, fn := range ctx.srcFuncs {
+		for _, block := range fn.Blocks {
+			for _, instr := range block.Instrs {
+				switch inst := instr.(type) {
+				case *ssa.Call:
+					ref := ctx.resolveLockAcquireRef(inst)
+					if ref == nil {
+						continue
+					}
+					alloc, ok := ref.base.(*ssa.Alloc)
+					if !ok || !ctx.isCopiedAlloc(alloc) {
+						continue
+					}
+					if mfk, ok := lockRefToMutexFieldKey(ref); ok {
+						report(fn, alloc.Pos(), mfk)
+					}
+
+				case *ssa.Return:
+					for _, result := range inst.Results {
+						named, st, ok := ctx.mutexStructType(result.Type())
+						if !ok || !isCopiedValue(result, 0) {
+							continue
+						}
+						for _, idx := range ctx.mutexFieldIndices(st) {
+							report(fn, inst.Pos(), mutexFieldKey{StructType: named, FieldIndex: idx})
+						}
+					}
+
+				case *ssa.MakeInterface:
+					named, st, ok := ctx.mutexStructType(inst.X.Type())
+					if !ok || !isCopiedValue(inst.X, 0) {
+						continue
+					}
+					pos := inst.Pos()
+					if !pos.IsValid() {
+						pos = inst.X.Pos()
+					}
+					for _, idx := range ctx.mutexFieldIndices(st) {
+						report(fn, pos, mutexFieldKey{StructType: named, FieldIndex: idx})
+					}
+				}
+			}
+		}
+	}
+}
+
+// resolveLockAcquireRef returns the lockRef of a static Lock/RLock call, or
+// nil if the call is not a lock acquisition.
+func (ctx *passContext) resolveLockAcquireRef(call *ssa.Call) *lockRef {
+	common := call.Common()
+	callee := common.StaticCallee()
+	if callee == nil || len(common.Args) == 0 || !isLockAcquire(callee.Name()) {
+		return nil
+	}
+	recv := common.Args[0]
+	if ctx.isMutexReceiver(recv) {
+		return ctx.resolveLockRef(recv)
+	}
+	return ctx.resolveEmbeddedMutexRef(recv, callee.Name())
+}
+
+// isCopiedAlloc returns true if the allocation holds a copy of an existing
+// struct value, i.e. it is initialized by storing a copied value. Cells
+// holding a pointer (e.g. a lifted receiver captured by a closure) are not
+// copies of the struct.
+func (ctx *passContext) isCopiedAlloc(alloc *ssa.Alloc) bool {
+	ptr, ok := alloc.Type().(*types.Pointer)
+	if !ok {
+		return false
+	}
+	if _, _, ok := ctx.mutexStructType(ptr.Elem()); !ok {
+		return false
+	}
+	refs := alloc.Referrers()
+	if refs == nil {
+		return false
+	}
+	for _, instr := range *refs {
+		store, ok := instr.(*ssa.Store)
+		if !ok || store.Addr != alloc {
+			continue
+		}
+		if isCopiedValue(store.Val, 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCopiedValue returns true if v is a by-value copy of an existing struct
+// rather than a freshly constructed one: a parameter (including a value
+// receiver), a load through a pointer, or a range-over-map element. Loads
+// from local allocations are traced through their stores, so returning a
+// composite literal is not a copy while returning *p is.
+func isCopiedValue(v ssa.Value, depth int) bool {
+	const maxDepth = 4
+	if depth >= maxDepth {
+		return false
+	}
+	switch val := v.(type) {
+	case *ssa.Parameter:
+		return true
+	case *ssa.Extract:
+		_, isNext := val.Tuple.(*ssa.Next)
+		return isNext
+	case *ssa.UnOp:
+		if val.Op != token.MUL {
+			return false
+		}
+		alloc, ok := val.X.(*ssa.Alloc)
+		if !ok {
+			return true
+		}
+		refs := alloc.Referrers()
+		if refs == nil {
+			return false
+		}
+		for _, instr := range *refs {
+			if store, ok := instr.(*ssa.Store); ok && store.Addr == alloc && isCopiedValue(store.Val, depth+1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mutexStructType returns the named struct type of t if it is a struct value
+// (not a pointer) that directly contains a mutex field.
+func (ctx *passContext) mutexStructType(t types.Type) (*types.Named, *types.Struct, bool) {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return nil, nil, false
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok || len(ctx.mutexFieldIndices(st)) == 0 {
+		return nil, nil, false
+	}
+	return named, st, true
+}
@@ -32,11 +32,27 @@ func (f *FieldGuardFact) String() string {
 	return fmt.Sprintf("FieldGuardFact{%s}", strings.Join(parts, " "))
 }
 
-// MutexRef is a gob-encodable reference to a mutex field.
+// GlobalGuardFact is exported as an analysis.Fact attached to the *types.Var
+// of a package-level variable. It records which package-level mutex of the
+// same package guards the variable.
+type GlobalGuardFact struct {
+	Mutex          string // name of the guarding package-level mutex
+	NeedsExclusive bool   // true if the guard needs an exclusive lock (writes observed)
+}
+
+func (*GlobalGuardFact) AFact() {}
+
+func (f *GlobalGuardFact) String() string {
+	return fmt.Sprintf("GlobalGuardFact{%s}", f.Mutex)
+}
+
+// MutexRef is a gob-encodable reference to a mutex field, or to a
+// package-level mutex when VarName is set.
 type MutexRef struct {
 	PkgPath    string
 	TypeName   string
 	FieldIndex int
+	VarName    string
 }
 
 // FuncLockFact is exported as an analysis.Fact attached to *types.Func.
@@ -46,6 +62,17 @@ type FuncLockFact struct {
 	Acquires           []MutexRef
 	AcquiresTransitive []MutexRef
 	ReturnsHolding     []MutexRef
+	ReleasesHeld       []MutexRef // locks held by callers that the function releases (//mu:releases)
+	MayBlock           bool
+	LockedCallbacks    []LockedCallback
+}
+
+// LockedCallback records that a function invokes its function-typed parameter
+// Param (index including the receiver) while holding the given mutexes.
+type LockedCallback struct {
+	Param     int
+	Exclusive []MutexRef
+	Shared    []MutexRef
 }
 
 func (*FuncLockFact) AFact() {}
@@ -57,23 +84,61 @@ func (f *FuncLockFact) String() string {
 		}
 		parts := make([]string, len(refs))
 		for i, r := range refs {
+			if r.VarName != "" {
+				parts[i] = r.VarName
+				continue
+			}
 			parts[i] = fmt.Sprintf("%s.%d", r.TypeName, r.FieldIndex)
 		}
 		return "[" + strings.Join(parts, " ") + "]"
 	}
-	return fmt.Sprintf("FuncLockFact{requires=%s acquires=%s}", fmtRefs(f.Requires), fmtRefs(f.Acquires))
+	s := fmt.Sprintf("FuncLockFact{requires=%s acquires=%s", fmtRefs(f.Requires), fmtRefs(f.Acquires))
+	if len(f.ReleasesHeld) > 0 {
+		s += " releases=" + fmtRefs(f.ReleasesHeld)
+	}
+	if f.MayBlock {
+		s += " mayblock"
+	}
+	for _, cb := range f.LockedCallbacks {
+		s += fmt.Sprintf(" callback%d=%s", cb.Param, fmtRefs(append(append([]MutexRef(nil), cb.Exclusive...), cb.Shared...)))
+	}
+	return s + "}"
 }
 
 // ConcurrentFact is exported as an analysis.Fact attached to *types.Func.
-// It marks a function as a concurrent entrypoint.
-type ConcurrentFact struct{}
+// It marks a function as a concurrent entrypoint, and records the
+// function-typed parameters it runs concurrently: the callbacks callers pass
+// there are concurrent entrypoints.
+type ConcurrentFact struct {
+	Entrypoint bool
+	Params     []int // parameter indexes, including the receiver
+}
 
 func (*ConcurrentFact) AFact() {}
 
-func (*ConcurrentFact) String() string { return "ConcurrentFact" }
+func (f *ConcurrentFact) String() string {
+	var parts []string
+	if f.Entrypoint {
+		parts = append(parts, "entrypoint")
+	}
+	if len(f.Params) > 0 {
+		params := make([]string, len(f.Params))
+		for i, p := range f.Params {
+			params[i] = fmt.Sprint(p)
+		}
+		parts = append(parts, "params=["+strings.Join(params, " ")+"]")
+	}
+	return "ConcurrentFact{" + strings.Join(parts, " ") + "}"
+}
 
 // mutexFieldKeyToRef converts an internal mutexFieldKey to a serializable MutexRef.
 func mutexFieldKeyToRef(mfk mutexFieldKey) MutexRef {
+	if mfk.Global != nil {
+		return MutexRef{
+			PkgPath: mfk.Global.Pkg().Path(),
+			VarName: mfk.Global.Name(),
+		}
+	}
 	return MutexRef{
 		PkgPath:    mfk.StructType.Obj().Pkg().Path(),
 		TypeName:   mfk.StructType.Obj().Name(),
@@ -98,6 +163,14 @@ func (ctx *passContext) mutexRefToKey(ref MutexRef) (mutexFieldKey, bool) {
 		return mutexFieldKey{}, false
 	}
 
+	if ref.VarName != "" {
+		v, ok := pkg.Scope().Lookup(ref.VarName).(*types.Var)
+		if !ok || !ctx.isMutexType(v.Type()) {
+			return mutexFieldKey{}, false
+		}
+		return mutexFieldKey{Global: v}, true
+	}
+
 	obj := pkg.Scope().Lookup(ref.TypeName)
 	if obj == nil {
 		return mutexFieldKey{}, false
@@ -136,6 +209,9 @@ func mutexFieldKeySetToRefs(set map[mutexFieldKey]bool) []MutexRef {
 		if refs[i].TypeName != refs[j].TypeName {
 			return refs[i].TypeName < refs[j].TypeName
 		}
+		if refs[i].VarName != refs[j].VarName {
+			return refs[i].VarName < refs[j].VarName
+		}
 		return refs[i].FieldIndex < refs[j].FieldIndex
 	})
 	return refs
@@ -148,6 +224,7 @@ func (ctx *passContext) importFacts() {
 		return
 	}
 	ctx.importFieldGuardFacts()
+	ctx.importGlobalGuardFacts()
 	ctx.importFuncLockFacts()
 	ctx.importConcurrentFacts()
 }
@@ -184,6 +261,27 @@ func (ctx *passContext) importFieldGuardFacts() {
 	}
 }
 
+// importGlobalGuardFacts imports GlobalGuardFact for imported package-level
+// variables that appear in observations.
+func (ctx *passContext) importGlobalGuardFacts() {
+	for v := range ctx.globalObservations {
+		if v.Pkg() == ctx.pass.Pkg {
+			continue
+		}
+
+		var fact GlobalGuardFact
+		if !ctx.pass.ImportObjectFact(v, &fact) {
+			continue
+		}
+
+		mutex, ok := v.Pkg().Scope().Lookup(fact.Mutex).(*types.Var)
+		if !ok {
+			continue
+		}
+		ctx.globalGuards[v] = globalGuardInfo{Mutex: mutex, NeedsExclusive: fact.NeedsExclusive}
+	}
+}
+
 // importFuncLockFacts imports FuncLockFact for imported callees.
 func (ctx *passContext) importFuncLockFacts() {
 	seen := make(map[*ssa.Function]bool)
@@ -204,29 +302,104 @@ func (ctx *passContext) importFuncLockFacts() {
 		if !ctx.pass.ImportObjectFact(callee.Object(), &fact) {
 			continue
 		}
+		ctx.applyFuncLockFact(ctx.getOrCreateFuncFacts(callee), &fact)
+	}
+}
 
-		facts := ctx.getOrCreateFuncFacts(callee)
-		for _, ref := range fact.Requires {
-			if mfk, ok := ctx.mutexRefToKey(ref); ok {
-				facts.Requires[mfk] = true
-			}
+// calleeLockFacts returns the lock facts of callee. Facts of imported callees
+// are imported on demand, since the SSA walk runs before Phase 1.5.
+func (ctx *passContext) calleeLockFacts(callee *ssa.Function) *funcLockFacts {
+	if facts, ok := ctx.funcFacts[callee]; ok {
+		return facts
+	}
+	if len(ctx.pass.Analyzer.FactTypes) == 0 || callee.Object() == nil || callee.Object().Pkg() == ctx.pass.Pkg {
+		return nil
+	}
+	var fact FuncLockFact
+	if !ctx.pass.ImportObjectFact(callee.Object(), &fact) {
+		return nil
+	}
+	facts := ctx.getOrCreateFuncFacts(callee)
+	ctx.applyFuncLockFact(facts, &fact)
+	return facts
+}
+
+// applyFuncLockFact adds an imported FuncLockFact to facts.
+func (ctx *passContext) applyFuncLockFact(facts *funcLockFacts, fact *FuncLockFact) {
+	for _, ref := range fact.Requires {
+		if mfk, ok := ctx.mutexRefToKey(ref); ok {
+			facts.Requires[mfk] = true
+		}
+	}
+	for _, ref := range fact.Acquires {
+		if mfk, ok := ctx.mutexRefToKey(ref); ok {
+			facts.Acquires[mfk] = true
+		}
+	}
+	for _, ref := range fact.AcquiresTransitive {
+		if mfk, ok := ctx.mutexRefToKey(ref); ok {
+			facts.AcquiresTransitive[mfk] = true
+		}
+	}
+	for _, ref := range fact.ReturnsHolding {
+		if mfk, ok := ctx.mutexRefToKey(ref); ok {
+			facts.ReturnsHolding[mfk] = true
+		}
+	}
+	for _, ref := range fact.ReleasesHeld {
+		if mfk, ok := ctx.mutexRefToKey(ref); ok {
+			facts.ReleasesHeld[mfk] = true
 		}
-		for _, ref := range fact.Acquires {
+	}
+	if fact.MayBlock {
+		facts.MayBlock = true
+	}
+	ctx.importLockedCallbacks(facts, fact.LockedCallbacks)
+}
+
+// importLockedCallbacks converts serialized lock wrapper callbacks into facts.
+func (ctx *passContext) importLockedCallbacks(facts *funcLockFacts, callbacks []LockedCallback) {
+	for _, cb := range callbacks {
+		held := make(map[mutexFieldKey]bool)
+		for _, ref := range cb.Exclusive {
 			if mfk, ok := ctx.mutexRefToKey(ref); ok {
-				facts.Acquires[mfk] = true
+				held[mfk] = true
 			}
 		}
-		for _, ref := range fact.AcquiresTransitive {
+		for _, ref := range cb.Shared {
 			if mfk, ok := ctx.mutexRefToKey(ref); ok {
-				facts.AcquiresTransitive[mfk] = true
+				held[mfk] = false
 			}
 		}
-		for _, ref := range fact.ReturnsHolding {
-			if mfk, ok := ctx.mutexRefToKey(ref); ok {
-				facts.ReturnsHolding[mfk] = true
+		facts.LockedCallbacks[cb.Param] = held
+	}
+}
+
+// lockedCallbacksToFact serializes lock wrapper callbacks, skipping parameters
+// invoked without any lock held. Sorted by parameter index for determinism.
+func lockedCallbacksToFact(callbacks map[int]map[mutexFieldKey]bool) []LockedCallback {
+	var result []LockedCallback
+	for param, held := range callbacks {
+		if len(held) == 0 {
+			continue
+		}
+		exclusive := make(map[mutexFieldKey]bool)
+		shared := make(map[mutexFieldKey]bool)
+		for mfk, excl := range held {
+			if excl {
+				exclusive[mfk] = true
+			} else {
+				shared[mfk] = true
 			}
 		}
+		result = append(result, LockedCallback{
+			Param:     param,
+			Exclusive: mutexFieldKeySetToRefs(exclusive),
+			Shared:    mutexFieldKeySetToRefs(shared),
+		})
 	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Param < result[j].Param })
+	return result
 }
 
 // importConcurrentFacts imports ConcurrentFact for imported callees and merges
@@ -247,7 +420,7 @@ func (ctx *passContext) importConcurrentFacts() {
 		seen[callee] = true
 
 		var fact ConcurrentFact
-		if ctx.pass.ImportObjectFact(callee.Object(), &fact) {
+		if ctx.pass.ImportObjectFact(callee.Object(), &fact) && fact.Entrypoint {
 			if ctx.annotations != nil {
 				ctx.annotations.concurrent[callee] = true
 			}
@@ -262,6 +435,7 @@ func (ctx *passContext) exportFacts() {
 		return
 	}
 	ctx.exportFieldGuardFacts()
+	ctx.exportGlobalGuardFacts()
 	ctx.exportFuncLockFacts()
 	ctx.exportConcurrentFacts()
 }
@@ -303,6 +477,20 @@ func (ctx *passContext) exportFieldGuardFacts() {
 	}
 }
 
+// exportGlobalGuardFacts exports GlobalGuardFact for exported package-level
+// variables with an inferred guard.
+func (ctx *passContext) exportGlobalGuardFacts() {
+	for v, guard := range ctx.globalGuards {
+		if v.Pkg() != ctx.pass.Pkg || !v.Exported() {
+			continue
+		}
+		ctx.pass.ExportObjectFact(v, &GlobalGuardFact{
+			Mutex:          guard.Mutex.Name(),
+			NeedsExclusive: guard.NeedsExclusive,
+		})
+	}
+}
+
 // exportFuncLockFacts exports FuncLockFact for exported functions with lock
 // requirements or acquisitions.
 func (ctx *passContext) exportFuncLockFacts() {
@@ -316,7 +504,8 @@ func (ctx *passContext) exportFuncLockFacts() {
 		if !fn.Object().Exported() {
 			continue
 		}
-		if len(facts.Requires) == 0 && len(facts.Acquires) == 0 && len(facts.AcquiresTransitive) == 0 && len(facts.ReturnsHolding) == 0 {
+		callbacks := lockedCallbacksToFact(facts.LockedCallbacks)
+		if len(facts.Requires) == 0 && len(facts.Acquires) == 0 && len(facts.AcquiresTransitive) == 0 && len(facts.ReturnsHolding) == 0 && len(facts.ReleasesHeld) == 0 && !facts.MayBlock && len(callbacks) == 0 {
 			continue
 		}
 
@@ -325,14 +514,30 @@ func (ctx *passContext) exportFuncLockFacts() {
 			Acquires:           mutexFieldKeySetToRefs(facts.Acquires),
 			AcquiresTransitive: mutexFieldKeySetToRefs(facts.AcquiresTransitive),
 			ReturnsHolding:     mutexFieldKeySetToRefs(facts.ReturnsHolding),
+			ReleasesHeld:       mutexFieldKeySetToRefs(facts.ReleasesHeld),
+			MayBlock:           facts.MayBlock,
+			LockedCallbacks:    callbacks,
 		})
 	}
 }
 
-// exportConcurrentFacts exports ConcurrentFact for exported concurrent entrypoints.
+// exportConcurrentFacts exports ConcurrentFact for exported concurrent
+// entrypoints and exported functions with concurrent parameters.
 func (ctx *passContext) exportConcurrentFacts() {
-	entrypoints := ctx.detectConcurrentEntrypoints()
-	for fn := range entrypoints {
+	facts := make(map[*ssa.Function]*ConcurrentFact)
+	for fn := range ctx.entrypoints {
+		facts[fn] = &ConcurrentFact{Entrypoint: true}
+	}
+	for fn, params := range ctx.concurrentParams {
+		if len(params) == 0 {
+			continue
+		}
+		if facts[fn] == nil {
+			facts[fn] = &ConcurrentFact{}
+		}
+		facts[fn].Params = sortedParams(params)
+	}
+	for fn, fact := range facts {
 		if fn.Object() == nil {
 			continue
 		}
@@ -342,6 +547,6 @@ func (ctx *passContext) exportConcurrentFacts() {
 		if !fn.Object().Exported() {
 			continue
 		}
-		ctx.pass.ExportObjectFact(fn.Object(), &ConcurrentFact{})
+		ctx.pass.ExportObjectFact(fn.Object(), fact)
 	}
 }
@@ -0,0 +1,214 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"path/filepath"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// Finding is the structured form of a diagnostic. The analyzer returns the
+// findings of a package as its result ([]Finding) so that drivers can render
+// them in machine-readable formats. The JSON encoding of Finding is the stable
+// schema of `golintmu -format=json`: fields are only ever added, never renamed.
+type Finding struct {
+	Check    string   `json:"check"`          // catalog ID, "C1".."C16"
+	Severity string   `json:"severity"`       // effective severity: "error", "warning" or "note"
+	Message  string   `json:"message"`        // diagnostic message, without provenance lines
+	Pos      Position `json:"pos"`            // position of the diagnostic
+	Package  string   `json:"package"`        // import path of the analyzed package
+	Func     string   `json:"func,omitempty"` // function containing the diagnostic
+
+	Type     string `json:"type,omitempty"`     // package-qualified struct type of the field or mutex
+	Field    string `json:"field,omitempty"`    // accessed struct field
+	Variable string `json:"variable,omitempty"` // accessed package-level variable, package-qualified
+	Mutex    string `json:"mutex,omitempty"`    // mutex involved ("S.mu" or "mu"); the inferred guard for access checks
+	Mode     string `json:"mode,omitempty"`     // "exclusive" or "shared": mode held, or required for missing-lock findings
+	Access   string `json:"access,omitempty"`   // "read" or "write" for field and variable accesses
+
+	Related    []RelatedLocation  `json:"related,omitempty"`    // secondary positions (acquire site, cycle edges, ...)
+	Provenance [][]ProvenanceStep `json:"provenance,omitempty"` // why a callee requires a lock or may block (-verbose)
+}
+
+// Position is a resolved source position.
+type Position struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// RelatedLocation is a secondary position attached to a finding.
+type RelatedLocation struct {
+	Pos     Position `json:"pos"`
+	Message string   `json:"message"`
+}
+
+// ProvenanceStep is one hop of a provenance chain: Func calls Target, accesses
+// Target, or blocks on Target at Pos.
+type ProvenanceStep struct {
+	Func   string   `json:"func"`
+	Kind   string   `json:"kind"` // "calls", "accesses" or "blocks"
+	Target string   `json:"target"`
+	Pos    Position `json:"pos"`
+}
+
+// Lock modes and access kinds used in findings.
+const (
+	modeExclusive = "exclusive"
+	modeShared    = "shared"
+	accessRead    = "read"
+	accessWrite   = "write"
+)
+
+// report records f and emits it as a diagnostic at pos, unless its check is
+// disabled, the package excluded, or f recorded in the baseline. The
+// diagnostic is tagged with the catalog ID, carries the related positions,
+// and in verbose mode appends the provenance chains to the message. A
+// severity set in f replaces the catalog default of its check, but not a
+// -severity or configuration override.
+func (ctx *passContext) report(pos token.Pos, f Finding, related ...analysis.RelatedInformation) {
+	ctx.reportWithFixes(pos, f, nil, related...)
+}
+
+// reportWithFixes is report for diagnostics offering suggested fixes.
+func (ctx *passContext) reportWithFixes(pos token.Pos, f Finding, fixes []analysis.SuggestedFix, related ...analysis.RelatedInformation) {
+	pkg := ctx.pkgAt(pos)
+	if ctx.configOf(pkg).packageExcluded(pkg.Path()) || !ctx.checksOf(pkg).enabled(f.Check) {
+		return
+	}
+	f.Package = pkg.Path()
+	if ctx.inBaseline(f) {
+		return
+	}
+	sevs := ctx.severitiesOf(pkg)
+	if _, overridden := sevs[f.Check]; overridden || f.Severity == "" {
+		f.Severity = sevs.severity(f.Check)
+	}
+	f.Pos = ctx.position(pos)
+	for _, r := range related {
+		f.Related = append(f.Related, RelatedLocation{Pos: ctx.position(r.Pos), Message: r.Message})
+	}
+	ctx.findings = append(ctx.findings, f)
+
+	msg := f.Message
+	if ctx.verbose {
+		for _, line := range formatProvenance(f.Provenance) {
+			msg += "\n" + line
+		}
+	}
+	ctx.pass.Report(analysis.Diagnostic{
+		Pos:            pos,
+		Category:       f.Check,
+		Message:        msg,
+		Related:        related,
+		SuggestedFixes: fixes,
+	})
+}
+
+// position resolves pos against the pass's file set.
+func (ctx *passContext) position(pos token.Pos) Position {
+	p := ctx.pass.Fset.Position(pos)
+	return Position{File: p.Filename, Line: p.Line, Column: p.Column}
+}
+
+// funcName returns the name of fn relative to the current package (its own
+// package in whole-program mode).
+func (ctx *passContext) funcName(fn *ssa.Function) string {
+	if fn == nil {
+		return ""
+	}
+	return fn.RelString(ctx.pkgOf(fn))
+}
+
+// qualifiedTypeName returns "pkgpath.Name" for a named type.
+func qualifiedTypeName(named *types.Named) string {
+	obj := named.Obj()
+	if obj.Pkg() == nil {
+		return obj.Name()
+	}
+	return obj.Pkg().Path() + "." + obj.Name()
+}
+
+// qualifiedVarName returns "pkgpath.name" for a package-level variable.
+func qualifiedVarName(v *types.Var) string {
+	if v.Pkg() == nil {
+		return v.Name()
+	}
+	return v.Pkg().Path() + "." + v.Name()
+}
+
+// withMutex fills the Type and Mutex fields of f for mfk.
+func withMutex(f Finding, mfk mutexFieldKey) Finding {
+	f.Mutex = mutexFieldKeyName(mfk)
+	if mfk.StructType != nil {
+		f.Type = qualifiedTypeName(mfk.StructType)
+	}
+	return f
+}
+
+// withLockRef fills the Type and Mutex fields of f for ref.
+func withLockRef(f Finding, ref lockRef) Finding {
+	if mfk, ok := lockRefToMutexFieldKey(&ref); ok {
+		return withMutex(f, mfk)
+	}
+	f.Mutex = lockRefName(ref)
+	return f
+}
+
+// lockMode returns the finding mode for an exclusive flag.
+func lockMode(exclusive bool) string {
+	if exclusive {
+		return modeExclusive
+	}
+	return modeShared
+}
+
+// accessKind returns the finding access kind and the lock mode it requires.
+func accessKind(isRead bool) (access, mode string) {
+	if isRead {
+		return accessRead, modeShared
+	}
+	return accessWrite, modeExclusive
+}
+
+// relatedAt builds a related position for a diagnostic.
+func relatedAt(pos token.Pos, format string, args ...any) analysis.RelatedInformation {
+	return analysis.RelatedInformation{Pos: pos, Message: fmt.Sprintf(format, args...)}
+}
+
+// formatProvenance renders provenance chains as tab-prefixed lines, separating
+// chains with a blank line.
+func formatProvenance(chains [][]ProvenanceStep) []string {
+	var lines []string
+	for i, chain := range chains {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		for _, step := range chain {
+			lines = append(lines, formatProvenanceStep(step))
+		}
+	}
+	return lines
+}
+
+// formatProvenanceStep renders one provenance step.
+func formatProvenanceStep(step ProvenanceStep) string {
+	return fmt.Sprintf("\t%s at %s:%d:%d", step.Description(),
+		filepath.Base(step.Pos.File), step.Pos.Line, step.Pos.Column)
+}
+
+// Description renders the step without its position, e.g.
+// "handler() calls helper()".
+func (s ProvenanceStep) Description() string {
+	switch s.Kind {
+	case "calls":
+		return fmt.Sprintf("%s() calls %s()", s.Func, s.Target)
+	case "blocks":
+		return fmt.Sprintf("%s() blocks on %s", s.Func, s.Target)
+	default:
+		return fmt.Sprintf("%s() %s %s", s.Func, s.Kind, s.Target)
+	}
+}
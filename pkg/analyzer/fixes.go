@@ -0,0 +1,304 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ssa"
+)
+
+// Suggested fixes for the mechanical diagnostics. Each builder returns nil
+// when the source does not have the expected shape; the diagnostic is then
+// reported without a fix.
+
+// pathEnclosing returns the AST path from the innermost node enclosing pos up
+// to the file.
+func (ctx *passContext) pathEnclosing(pos token.Pos) []ast.Node {
+	if !pos.IsValid() {
+		return nil
+	}
+	for _, f := range ctx.pass.Files {
+		if f.FileStart <= pos && pos < f.FileEnd {
+			path, _ := astutil.PathEnclosingInterval(f, pos, pos)
+			return path
+		}
+	}
+	return nil
+}
+
+// lockCallAt returns the method call of a lock operation reported at pos:
+// the call whose opening parenthesis is at pos, or the call of the defer
+// statement at pos. It also returns the path enclosing the call.
+func (ctx *passContext) lockCallAt(pos token.Pos) (*ast.SelectorExpr, []ast.Node) {
+	path := ctx.pathEnclosing(pos)
+	for i, n := range path {
+		var call *ast.CallExpr
+		switch n := n.(type) {
+		case *ast.DeferStmt:
+			if n.Defer == pos {
+				call = n.Call
+			}
+		case *ast.CallExpr:
+			if n.Lparen == pos {
+				call = n
+			}
+		}
+		if call == nil {
+			continue
+		}
+		sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+		if !ok {
+			return nil, nil
+		}
+		return sel, path[i:]
+	}
+	return nil, nil
+}
+
+// renameMethodFix replaces the method name of a lock operation, e.g.
+// Unlock -> RUnlock.
+func (ctx *passContext) renameMethodFix(pos token.Pos, from, to string) []analysis.SuggestedFix {
+	sel, _ := ctx.lockCallAt(pos)
+	if sel == nil || sel.Sel.Name != from {
+		return nil
+	}
+	return []analysis.SuggestedFix{{
+		Message: "Replace " + from + "() with " + to + "()",
+		TextEdits: []analysis.TextEdit{{
+			Pos:     sel.Sel.Pos(),
+			End:     sel.Sel.End(),
+			NewText: []byte(to),
+		}},
+	}}
+}
+
+// deferUnlockFix inserts `defer x.Unlock()` (or RUnlock) right after the
+// statement acquiring a leaked lock. It applies only when fn never unlocks
+// the mutex explicitly, so that the deferred unlock cannot double-unlock, and
+// when the acquire is not inside a loop.
+func (ctx *passContext) deferUnlockFix(fn *ssa.Function, acquirePos token.Pos, ref lockRef) []analysis.SuggestedFix {
+	mfk, ok := lockRefToMutexFieldKey(&ref)
+	if !ok {
+		return nil
+	}
+	if facts := ctx.funcFacts[fn]; facts != nil && facts.Releases[mfk] {
+		return nil
+	}
+	sel, path := ctx.lockCallAt(acquirePos)
+	if sel == nil || len(path) < 3 || !isSideEffectFree(sel.X) {
+		return nil
+	}
+	unlock := map[string]string{"Lock": "Unlock", "RLock": "RUnlock"}[sel.Sel.Name]
+	if unlock == "" {
+		return nil
+	}
+	stmt, ok := path[1].(*ast.ExprStmt)
+	if !ok || !isStatementList(path[2]) || insideLoop(path) {
+		return nil
+	}
+
+	text := "defer " + types.ExprString(sel.X) + "." + unlock + "()"
+	end := ctx.lineEnd(path, stmt)
+	return []analysis.SuggestedFix{{
+		Message: "Add " + text,
+		TextEdits: []analysis.TextEdit{{
+			Pos:     end,
+			End:     end,
+			NewText: []byte("\n" + ctx.indentation(stmt.Pos()) + text),
+		}},
+	}}
+}
+
+// wrapInLockFix surrounds the statement containing an unguarded access with
+// lockExpr.Lock() and lockExpr.Unlock(). The exclusive lock is used even for
+// reads so that fixes for several accesses in one statement coincide. It
+// applies only to simple statements of functions that acquire no lock, and
+// not when the function acquires mfk, the mutex to hold, through a callee
+// (e.g. s.count = s.next() with next locking s.mu): the fix would deadlock.
+func (ctx *passContext) wrapInLockFix(fn *ssa.Function, pos token.Pos, mfk mutexFieldKey, lockExpr func(stmt ast.Stmt) string) []analysis.SuggestedFix {
+	if facts := ctx.funcFacts[fn]; facts != nil && (len(facts.Acquires) > 0 || facts.AcquiresTransitive[mfk]) {
+		return nil
+	}
+	if ctx.seededCallbacks[fn] {
+		return nil
+	}
+
+	path := ctx.pathEnclosing(pos)
+	for i, n := range path {
+		stmt, ok := n.(ast.Stmt)
+		if !ok {
+			continue
+		}
+		switch stmt.(type) {
+		case *ast.ExprStmt, *ast.AssignStmt, *ast.IncDecStmt, *ast.SendStmt:
+		default:
+			return nil
+		}
+		if i+1 >= len(path) || !isStatementList(path[i+1]) {
+			return nil
+		}
+		mu := lockExpr(stmt)
+		if mu == "" {
+			return nil
+		}
+		indent := ctx.indentation(stmt.Pos())
+		end := ctx.lineEnd(path, stmt)
+		return []analysis.SuggestedFix{{
+			Message: "Hold " + mu + " around the access",
+			TextEdits: []analysis.TextEdit{
+				{Pos: stmt.Pos(), End: stmt.Pos(), NewText: []byte(mu + ".Lock()\n" + indent)},
+				{Pos: end, End: end, NewText: []byte("\n" + indent + mu + ".Unlock()")},
+			},
+		}}
+	}
+	return nil
+}
+
+// fieldLockExpr returns a function building the guard expression for an
+// access to key in a statement: the base of the field selector followed by
+// the mutex field, e.g. "c.mu" for "c.count++".
+func (ctx *passContext) fieldLockExpr(key fieldKey, mutexName string) func(ast.Stmt) string {
+	st, ok := key.StructType.Underlying().(*types.Struct)
+	if !ok || key.FieldIndex >= st.NumFields() {
+		return func(ast.Stmt) string { return "" }
+	}
+	field := st.Field(key.FieldIndex)
+	return func(stmt ast.Stmt) string {
+		var base ast.Expr
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			if base != nil {
+				return false
+			}
+			if _, ok := n.(*ast.FuncLit); ok {
+				return false
+			}
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if s := ctx.pass.TypesInfo.Selections[sel]; s != nil && s.Obj() == field {
+				base = sel.X
+			}
+			return true
+		})
+		if base == nil || !isSideEffectFree(base) {
+			return ""
+		}
+		return types.ExprString(base) + "." + mutexName
+	}
+}
+
+// deleteCommentFix removes a comment: the whole line when the comment is
+// alone on it, the comment and the blank space before it when trailing.
+func (ctx *passContext) deleteCommentFix(c *ast.Comment) []analysis.SuggestedFix {
+	prefix, ok := ctx.linePrefix(c.Pos())
+	if !ok {
+		return nil
+	}
+	start, end := c.Pos(), c.End()
+	if strings.TrimLeft(prefix, " \t") == "" {
+		start -= token.Pos(len(prefix))
+		tf := ctx.pass.Fset.File(c.Pos())
+		if tf.Line(c.End()) < tf.LineCount() {
+			end = tf.LineStart(tf.Line(c.End()) + 1)
+		}
+	} else {
+		start -= token.Pos(len(prefix) - len(strings.TrimRight(prefix, " \t")))
+	}
+	return []analysis.SuggestedFix{{
+		Message:   "Remove the directive",
+		TextEdits: []analysis.TextEdit{{Pos: start, End: end}},
+	}}
+}
+
+// isSideEffectFree reports whether e is an identifier or a selector chain on
+// one, so that it can be repeated in a fix.
+func isSideEffectFree(e ast.Expr) bool {
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		return true
+	case *ast.SelectorExpr:
+		return isSideEffectFree(e.X)
+	case *ast.StarExpr:
+		return isSideEffectFree(e.X)
+	}
+	return false
+}
+
+// isStatementList reports whether statements can be inserted next to a child
+// of n.
+func isStatementList(n ast.Node) bool {
+	switch n.(type) {
+	case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+		return true
+	}
+	return false
+}
+
+// insideLoop reports whether path, from a node up to the file, crosses a loop
+// before reaching the enclosing function.
+func insideLoop(path []ast.Node) bool {
+	for _, n := range path {
+		switch n.(type) {
+		case *ast.ForStmt, *ast.RangeStmt:
+			return true
+		case *ast.FuncDecl, *ast.FuncLit:
+			return false
+		}
+	}
+	return false
+}
+
+// lineEnd returns the end of stmt, including a trailing comment on the same
+// line. path ends with the file containing stmt.
+func (ctx *passContext) lineEnd(path []ast.Node, stmt ast.Stmt) token.Pos {
+	file, ok := path[len(path)-1].(*ast.File)
+	if !ok {
+		return stmt.End()
+	}
+	line := ctx.pass.Fset.Position(stmt.End()).Line
+	for _, cg := range file.Comments {
+		if cg.Pos() >= stmt.End() && ctx.pass.Fset.Position(cg.Pos()).Line == line {
+			return cg.End()
+		}
+	}
+	return stmt.End()
+}
+
+// indentation returns the whitespace preceding pos on its line.
+func (ctx *passContext) indentation(pos token.Pos) string {
+	prefix, ok := ctx.linePrefix(pos)
+	if !ok || strings.TrimLeft(prefix, " \t") != "" {
+		return ""
+	}
+	return prefix
+}
+
+// startsLine reports whether only whitespace precedes pos on its line. It
+// assumes so when the file cannot be read.
+func (ctx *passContext) startsLine(pos token.Pos) bool {
+	prefix, ok := ctx.linePrefix(pos)
+	return !ok || strings.TrimLeft(prefix, " \t") == ""
+}
+
+// linePrefix returns the source text preceding pos on its line.
+func (ctx *passContext) linePrefix(pos token.Pos) (string, bool) {
+	tf := ctx.pass.Fset.File(pos)
+	if tf == nil {
+		return "", false
+	}
+	content, err := ctx.pass.ReadFile(tf.Name())
+	if err != nil {
+		return "", false
+	}
+	start := tf.Offset(tf.LineStart(tf.Line(pos)))
+	end := tf.Offset(pos)
+	if end > len(content) || start > end {
+		return "", false
+	}
+	return string(content[start:end]), true
+}
@@ -0,0 +1,306 @@
+package analyzer
+
+import (
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// globalObservation records a single access to a package-level variable with
+// the global mutexes held at that point.
+type globalObservation struct {
+	HeldMutexes []heldGlobalMutex
+	IsRead      bool
+	Func        *ssa.Function
+	Pos         token.Pos
+}
+
+// heldGlobalMutex records a package-level mutex held at a program point.
+type heldGlobalMutex struct {
+	Mutex     *types.Var
+	Exclusive bool
+}
+
+// globalGuardInfo records the inferred guard for a package-level variable.
+type globalGuardInfo struct {
+	Mutex          *types.Var
+	NeedsExclusive bool // true when any observation is a write under the guard
+}
+
+// globalObsKey deduplicates global observations when blocks are re-walked.
+type globalObsKey struct {
+	v      *types.Var
+	pos    token.Pos
+	isRead bool
+}
+
+// resolveGlobalVar returns the package-level variable addressed by v, if v is
+// an *ssa.Global for a candidate variable (not a mutex, not a sync/atomic value,
+// not a synthetic global such as init$guard).
+func (ctx *passContext) resolveGlobalVar(v ssa.Value) (*types.Var, bool) {
+	g, ok := v.(*ssa.Global)
+	if !ok {
+		return nil, false
+	}
+	obj, ok := g.Object().(*types.Var)
+	if !ok {
+		return nil, false
+	}
+	if ctx.isMutexType(obj.Type()) || isAtomicType(obj.Type()) {
+		return nil, false
+	}
+	return obj, true
+}
+
+// globalMutexVar returns the package-level mutex variable a lockRef refers to.
+func globalMutexVar(ref lockRef) (*types.Var, bool) {
+	if ref.kind != globalLock {
+		return nil, false
+	}
+	g, ok := ref.base.(*ssa.Global)
+	if !ok {
+		return nil, false
+	}
+	obj, ok := g.Object().(*types.Var)
+	return obj, ok
+}
+
+// heldGlobalMutexes returns the package-level mutexes held in ls.
+func heldGlobalMutexes(ls *lockState) []heldGlobalMutex {
+	var held []heldGlobalMutex
+	for _, hl := range ls.held {
+		if v, ok := globalMutexVar(hl.ref); ok {
+			held = append(held, heldGlobalMutex{Mutex: v, Exclusive: hl.exclusive})
+		}
+	}
+	return held
+}
+
+// processGlobalAccess records an observation for a load from or store to a
+// package-level variable.
+func (ctx *passContext) processGlobalAccess(fn *ssa.Function, addr ssa.Value, isRead bool, pos token.Pos, ls *lockState) {
+	v, ok := ctx.resolveGlobalVar(addr)
+	if !ok {
+		return
+	}
+	key := globalObsKey{v: v, pos: pos, isRead: isRead}
+	if ctx.globalObservedAt[key] {
+		return
+	}
+	ctx.globalObservedAt[key] = true
+	ctx.globalObservations[v] = append(ctx.globalObservations[v], globalObservation{
+		HeldMutexes: heldGlobalMutexes(ls),
+		IsRead:      isRead,
+		Func:        fn,
+		Pos:         pos,
+	})
+}
+
+// updatesMap reports whether the map loaded by v is modified through it:
+// registry[k] = v and delete(registry, k) only load a package-level map, but
+// write to it like an assignment.
+func updatesMap(v ssa.Value) bool {
+	refs := v.Referrers()
+	if refs == nil {
+		return false
+	}
+	for _, ref := range *refs {
+		switch inst := ref.(type) {
+		case *ssa.MapUpdate:
+			if inst.Map == v {
+				return true
+			}
+		case *ssa.Call:
+			if b, ok := inst.Call.Value.(*ssa.Builtin); ok && b.Name() == "delete" && inst.Call.Args[0] == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isGlobalInitializationContext returns true if fn runs before the package's
+// globals are shared: init functions (including the synthesized package
+// initializer) and sync.Once callbacks.
+func (ctx *passContext) isGlobalInitializationContext(fn *ssa.Function) bool {
+	if fn.Name() == "init" || strings.HasPrefix(fn.Name(), "init#") {
+		return true
+	}
+	return ctx.onceFuncs[fn]
+}
+
+// inferGlobalGuards runs guard inference for package-level variables declared
+// in the current package. Only mutexes declared in the same package are
+// candidate guards.
+func (ctx *passContext) inferGlobalGuards() {
+	for v, observations := range ctx.globalObservations {
+		if !ctx.isLocal(v.Pkg()) {
+			continue
+		}
+
+		var filtered []globalObservation
+		for _, obs := range observations {
+			if ctx.isGlobalInitializationContext(obs.Func) {
+				continue
+			}
+			filtered = append(filtered, obs)
+		}
+		if len(filtered) == 0 {
+			continue
+		}
+
+		// Written only during initialization: immutable.
+		immutable := true
+		for _, obs := range filtered {
+			if !obs.IsRead {
+				immutable = false
+				break
+			}
+		}
+		if immutable {
+			continue
+		}
+
+		if guard, ok := inferGlobalGuard(v, filtered); ok {
+			ctx.globalGuards[v] = guard
+		}
+	}
+}
+
+// inferGlobalGuard picks the global mutex most frequently held during writes
+// (falling back to all accesses), mirroring inferFieldGuard.
+func inferGlobalGuard(v *types.Var, observations []globalObservation) (globalGuardInfo, bool) {
+	best := pickMostFrequentGlobalMutex(v, observations, true)
+	if best == nil {
+		best = pickMostFrequentGlobalMutex(v, observations, false)
+	}
+	if best == nil {
+		return globalGuardInfo{}, false
+	}
+
+	needsExclusive := false
+	for _, obs := range observations {
+		if obs.IsRead {
+			continue
+		}
+		if _, ok := holdsGlobalMutex(obs, best); ok {
+			needsExclusive = true
+			break
+		}
+	}
+	return globalGuardInfo{Mutex: best, NeedsExclusive: needsExclusive}, true
+}
+
+// pickMostFrequentGlobalMutex counts how often each same-package global mutex
+// is held across the observations. Ties are broken by declaration order.
+func pickMostFrequentGlobalMutex(v *types.Var, observations []globalObservation, writesOnly bool) *types.Var {
+	counts := make(map[*types.Var]int)
+	for _, obs := range observations {
+		if writesOnly && obs.IsRead {
+			continue
+		}
+		for _, hm := range obs.HeldMutexes {
+			if hm.Mutex.Pkg() != v.Pkg() {
+				continue
+			}
+			counts[hm.Mutex]++
+		}
+	}
+
+	candidates := make([]*types.Var, 0, len(counts))
+	for m := range counts {
+		candidates = append(candidates, m)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Pos() < candidates[j].Pos()
+	})
+
+	var best *types.Var
+	bestCount := 0
+	for _, m := range candidates {
+		if counts[m] > bestCount {
+			best = m
+			bestCount = counts[m]
+		}
+	}
+	return best
+}
+
+// holdsGlobalMutex returns whether the observation holds mutex, and in which mode.
+func holdsGlobalMutex(obs globalObservation, mutex *types.Var) (exclusive bool, held bool) {
+	for _, hm := range obs.HeldMutexes {
+		if hm.Mutex == mutex {
+			return hm.Exclusive, true
+		}
+	}
+	return false, false
+}
+
+// deriveGlobalRequirements records, for each function accessing a guarded
+// package-level variable without its guard, that callers must hold the guard.
+func (ctx *passContext) deriveGlobalRequirements() {
+	for v, guard := range ctx.globalGuards {
+		mfk := mutexFieldKey{Global: guard.Mutex}
+		for _, obs := range ctx.globalObservations[v] {
+			if ctx.isGlobalInitializationContext(obs.Func) {
+				continue
+			}
+			if _, held := holdsGlobalMutex(obs, guard.Mutex); held {
+				continue
+			}
+			// A contract replaces inference.
+			if ctx.contract(obs.Func) != nil {
+				continue
+			}
+			facts := ctx.getOrCreateFuncFacts(obs.Func)
+			facts.Requires[mfk] = true
+			if ctx.verbose {
+				facts.RequiresOrigin[mfk] = append(facts.RequiresOrigin[mfk],
+					requirementOrigin{
+						Global:    v,
+						AccessPos: obs.Pos,
+						IsRead:    obs.IsRead,
+					})
+			}
+		}
+	}
+}
+
+// checkGlobalViolations reports accesses to guarded package-level variables
+// without their guard, mirroring checkViolations for struct fields.
+func (ctx *passContext) checkGlobalViolations() {
+	for v, guard := range ctx.globalGuards {
+		for _, obs := range ctx.globalObservations[v] {
+			if ctx.isGlobalInitializationContext(obs.Func) {
+				continue
+			}
+			if !ctx.isConcurrent(obs.Func) {
+				continue
+			}
+
+			exclusive, held := holdsGlobalMutex(obs, guard.Mutex)
+			if held {
+				if !obs.IsRead && !exclusive {
+					ctx.reportGlobalWriteUnderSharedLock(obs, v, guard)
+				}
+				continue
+			}
+			if ctx.shouldSuppressDirectViolation(obs.Func, mutexFieldKey{Global: guard.Mutex}) {
+				continue
+			}
+			ctx.reportGlobalViolation(obs, v, guard)
+		}
+	}
+}
+
+// globalVarName returns the name of a package-level variable as written from
+// the package of fn: qualified with the package name when imported.
+func (ctx *passContext) globalVarName(fn *ssa.Function, v *types.Var) string {
+	if v.Pkg() == nil || v.Pkg() == ctx.pkgOf(fn) {
+		return v.Name()
+	}
+	return v.Pkg().Name() + "." + v.Name()
+}
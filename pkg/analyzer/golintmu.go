@@ -3,24 +3,34 @@ package analyzer
 import (
 	"go/token"
 	"go/types"
+	"reflect"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/buildssa"
 	"golang.org/x/tools/go/ssa"
 )
 
-var verbose bool
+var (
+	verbose             bool
+	requireReason       bool
+	reportUnused        bool
+	exportedEntrypoints bool
+)
 
 func init() {
 	Analyzer.Flags.BoolVar(&verbose, "verbose", false, "explain why each diagnostic was reported")
+	Analyzer.Flags.BoolVar(&requireReason, "require-reason", false, "report //mu:nolint and //mu:ignore directives without a reason")
+	Analyzer.Flags.BoolVar(&reportUnused, "report-unused-suppressions", false, "report //mu:nolint and //mu:ignore directives that suppress no diagnostic")
+	Analyzer.Flags.BoolVar(&exportedEntrypoints, "exported-entrypoints", false, "treat exported methods of types with a mutex field as concurrent entrypoints, for library packages")
 }
 
 var Analyzer = &analysis.Analyzer{
-	Name:      "golintmu",
-	Doc:       "detects inconsistent mutex locking of struct fields",
-	Run:       run,
-	Requires:  []*analysis.Analyzer{buildssa.Analyzer},
-	FactTypes: []analysis.Fact{(*FieldGuardFact)(nil), (*FuncLockFact)(nil), (*ConcurrentFact)(nil)},
+	Name:       "golintmu",
+	Doc:        "detects inconsistent mutex locking of struct fields",
+	Run:        run,
+	Requires:   []*analysis.Analyzer{buildssa.Analyzer},
+	ResultType: reflect.TypeOf([]Finding(nil)), // structured form of the package's diagnostics
+	FactTypes:  []analysis.Fact{(*FieldGuardFact)(nil), (*GlobalGuardFact)(nil), (*FuncLockFact)(nil), (*ConcurrentFact)(nil)},
 }
 
 // fieldKey uniquely identifies a struct field across the package.
@@ -43,6 +53,7 @@ type heldMutexField struct {
 type observation struct {
 	SameBaseMutexFields []heldMutexField
 	IsRead              bool
+	IsAtomic            bool // access through a sync/atomic function
 	Func                *ssa.Function
 	Pos                 token.Pos
 }
@@ -80,6 +91,43 @@ type lockLeakCandidate struct {
 	AcquirePos token.Pos // where the lock was acquired
 }
 
+// goroutineSpawnCandidate records a potential C8 diagnostic collected during
+// Phase 1 (SSA walk). Reporting is deferred to Phase 3.9.5 so that the spawned
+// function's AcquiresTransitive (propagated in Phase 3) is available.
+type goroutineSpawnCandidate struct {
+	Fn         *ssa.Function
+	Pos        token.Pos     // position of the go statement
+	Ref        lockRef       // lock held at the spawn point
+	AcquirePos token.Pos     // where the held lock was acquired
+	Target     *ssa.Function // spawned function (nil if unresolvable)
+}
+
+// crossGoroutineUnlock records a C12 finding: a lock held when a goroutine
+// was spawned is unlocked by a function running in that goroutine.
+type crossGoroutineUnlock struct {
+	Fn         *ssa.Function // function performing the unlock (in the spawned goroutine)
+	Pos        token.Pos     // unlock position
+	Ref        lockRef       // lockRef as seen by the unlocking function
+	SpawnPos   token.Pos     // position of the go statement
+	AcquirePos token.Pos     // where the parent goroutine acquired the lock
+}
+
+// lockHandoffKey identifies a (function, lockRef) pair where the lock is
+// handed off to a spawned goroutine, used to suppress C5, C8 and C13 for the
+// parent.
+type lockHandoffKey struct {
+	fn  *ssa.Function
+	ref lockRef
+}
+
+// handedOffUnlockKey identifies an unlock performed by a spawned goroutine
+// for a lock held by its parent, used to suppress C4 for it. The function is
+// part of the key: instantiations and synthetic wrappers share positions.
+type handedOffUnlockKey struct {
+	fn  *ssa.Function
+	pos token.Pos
+}
+
 // deferredLockTypoKey identifies a (function, lockRef) pair where a deferred
 // lock typo was detected, used to suppress C5 for the same pair.
 type deferredLockTypoKey struct {
@@ -89,18 +137,41 @@ type deferredLockTypoKey struct {
 
 // passContext holds state for a single analyzer pass.
 type passContext struct {
-	pass         *analysis.Pass
-	ssaPkg       *ssa.Package
-	srcFuncs     []*ssa.Function
-	observations map[fieldKey][]observation
-	guards       map[fieldKey]guardInfo
-	observedAt   map[obsKey]bool // deduplication set for observations
-	verbose      bool            // when true, append provenance explanations to interprocedural diagnostics
+	pass                *analysis.Pass
+	ssaPkg              *ssa.Package
+	srcFuncs            []*ssa.Function
+	observations        map[fieldKey][]observation
+	guards              map[fieldKey]guardInfo
+	observedAt          map[obsKey]bool // deduplication set for observations
+	verbose             bool            // when true, append provenance explanations to interprocedural diagnostics
+	config              *config         // project configuration file, if any
+	checks              checkSelection  // checks selected by -checks or the configuration
+	severities          severityMap     // severity overrides from the configuration and -severity
+	requireReason       bool            // report suppression directives without a reason (-require-reason)
+	reportUnused        bool            // report suppression directives that suppress nothing (-report-unused-suppressions)
+	exportedEntrypoints bool            // exported methods of types with a mutex field are entrypoints (-exported-entrypoints)
+
+	// Whole-program mode (see wholeprogram.go): the analyzed packages, the
+	// package of each of their files, and the configuration of each package.
+	// nil when analyzing a single package, ctx.pass.Pkg.
+	pkgs      []*types.Package
+	localPkgs map[*types.Package]bool
+	filePkgs  map[*token.File]*types.Package
+	configs   map[*types.Package]*config
+
+	// Baseline fingerprint counts (-baseline), and the occurrences already
+	// matched in this package.
+	baseline     map[string]int
+	baselineUsed map[string]int
 
 	// Interprocedural analysis state.
 	callSites []callSiteRecord
 	funcFacts map[*ssa.Function]*funcLockFacts
 
+	// Deferred C15 candidates (collected Phase 1, reported Phase 3.9.8).
+	// Keyed by return position to allow clearing stale candidates on block re-walks.
+	contractViolations map[token.Pos][]contractViolation
+
 	// Deferred C4 candidates (collected Phase 1, reported Phase 3.3).
 	unlockOfUnlockedCandidates []unlockOfUnlockedCandidate
 
@@ -108,6 +179,21 @@ type passContext struct {
 	// Keyed by return position to allow clearing stale candidates on block re-walks.
 	lockLeakCandidates map[token.Pos][]lockLeakCandidate
 
+	// Deferred C8 candidates (collected Phase 1, reported Phase 3.9.5).
+	// Keyed by go statement position to allow clearing stale candidates on block re-walks.
+	goroutineSpawnCandidates map[token.Pos][]goroutineSpawnCandidate
+
+	// Deferred C9 candidates (collected Phase 1, reported Phase 3.9.7).
+	// Keyed by blocking operation position to allow clearing stale candidates on block re-walks.
+	blockingOpCandidates map[token.Pos][]blockingOpCandidate
+
+	// C12 state: unlocks performed in a spawned goroutine for a lock held by
+	// its parent, the same unlocks by function and position (suppresses C4),
+	// and the parent-side handoffs (suppresses C5/C8/C13).
+	crossGoroutineUnlocks []crossGoroutineUnlock
+	handedOffUnlocks      map[handedOffUnlockKey]bool
+	lockHandoffs          map[lockHandoffKey]bool
+
 	// Set of (fn, lockRef) pairs where C7 fired — used to suppress C5 for the same pair.
 	deferredLockTypoReported map[deferredLockTypoKey]bool
 
@@ -118,45 +204,122 @@ type passContext struct {
 	// nil means "no entrypoints detected, treat all as concurrent".
 	// Non-nil maps functions reachable from concurrent entrypoints.
 	concurrentFuncs map[*ssa.Function]bool
+	entrypoints     map[*ssa.Function]bool // detected concurrent entrypoints
+
+	// Function-typed parameters (index including the receiver) that each
+	// function of the package runs concurrently (see concurrentparams.go).
+	concurrentParams map[*ssa.Function]map[int]bool
+	closureSites     map[*ssa.Function]*ssa.MakeClosure // closure → its MakeClosure, built lazily
+
+	// Package-level variable state: observations, deduplication set and
+	// inferred guards (global mutexes).
+	globalObservations map[*types.Var][]globalObservation
+	globalObservedAt   map[globalObsKey]bool
+	globalGuards       map[*types.Var]globalGuardInfo
+
+	// Fields accessed through sync/atomic functions at least once.
+	atomicFields map[fieldKey]bool
+
+	// Closures walked with a lock wrapper's locks held at entry.
+	seededCallbacks map[*ssa.Function]bool
+
+	// Functions passed to sync.Once.Do / sync.OnceFunc / OnceValue(s):
+	// synchronized initialization, treated like constructors.
+	onceFuncs map[*ssa.Function]bool
 
 	// Annotation directives parsed from comments.
 	annotations *annotations
+
+	// Structured form of every diagnostic reported, returned as the result.
+	findings []Finding
 }
 
 func run(pass *analysis.Pass) (any, error) {
 	ssaResult, ok := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
 	if !ok {
-		return nil, nil
+		return []Finding(nil), nil
+	}
+
+	cfg, err := packageConfig(pass)
+	if err != nil {
+		return nil, err
+	}
+	baseline, err := loadBaseline(baselinePath)
+	if err != nil {
+		return nil, err
 	}
 
-	ctx := &passContext{
-		pass:         pass,
-		ssaPkg:       ssaResult.Pkg,
-		srcFuncs:     ssaResult.SrcFuncs,
-		observations: make(map[fieldKey][]observation),
-		guards:       make(map[fieldKey]guardInfo),
-		observedAt:   make(map[obsKey]bool),
-		verbose:      verbose,
-		funcFacts:          make(map[*ssa.Function]*funcLockFacts),
-		lockOrderGraph:     newLockOrderGraph(),
-		lockLeakCandidates:      make(map[token.Pos][]lockLeakCandidate),
+	ctx := newPassContext(pass, ssaResult.Pkg, ssaResult.SrcFuncs, cfg, baseline)
+	ctx.analyze()
+	return ctx.findings, nil
+}
+
+// newPassContext returns the analysis state of srcFuncs, the source functions
+// of pass.
+func newPassContext(pass *analysis.Pass, ssaPkg *ssa.Package, srcFuncs []*ssa.Function, cfg *config, baseline map[string]int) *passContext {
+	return &passContext{
+		pass:                     pass,
+		ssaPkg:                   ssaPkg,
+		srcFuncs:                 srcFuncs,
+		observations:             make(map[fieldKey][]observation),
+		guards:                   make(map[fieldKey]guardInfo),
+		observedAt:               make(map[obsKey]bool),
+		verbose:                  verbose,
+		config:                   cfg,
+		checks:                   cfg.effectiveChecks(checks),
+		severities:               cfg.effectiveSeverities(severities),
+		requireReason:            requireReason,
+		reportUnused:             reportUnused,
+		exportedEntrypoints:      exportedEntrypoints,
+		baseline:                 baseline,
+		baselineUsed:             make(map[string]int),
+		funcFacts:                make(map[*ssa.Function]*funcLockFacts),
+		lockOrderGraph:           newLockOrderGraph(),
+		lockLeakCandidates:       make(map[token.Pos][]lockLeakCandidate),
+		contractViolations:       make(map[token.Pos][]contractViolation),
+		onceFuncs:                make(map[*ssa.Function]bool),
+		concurrentParams:         make(map[*ssa.Function]map[int]bool),
+		seededCallbacks:          make(map[*ssa.Function]bool),
+		atomicFields:             make(map[fieldKey]bool),
+		globalObservations:       make(map[*types.Var][]globalObservation),
+		globalObservedAt:         make(map[globalObsKey]bool),
+		globalGuards:             make(map[*types.Var]globalGuardInfo),
 		deferredLockTypoReported: make(map[deferredLockTypoKey]bool),
+		goroutineSpawnCandidates: make(map[token.Pos][]goroutineSpawnCandidate),
+		blockingOpCandidates:     make(map[token.Pos][]blockingOpCandidate),
+		handedOffUnlocks:         make(map[handedOffUnlockKey]bool),
+		lockHandoffs:             make(map[lockHandoffKey]bool),
 	}
+}
 
+// analyze runs the analysis phases, recording the findings in ctx.findings.
+func (ctx *passContext) analyze() {
 	// Phase 0: Parse annotation directives from comments.
 	ctx.parseAnnotations()
 
+	// Phase 0.5: Seed lock facts from function contracts (//mu:requires,
+	// //mu:acquires, //mu:releases).
+	ctx.seedContractFacts()
+
 	// Phase 1: Collect observations and call sites by walking SSA.
 	ctx.collectObservations()
 
+	// Phase 1.2: Detect sync.Once callbacks (synchronized initialization).
+	ctx.detectOnceCallbacks()
+
 	// Phase 1.5: Import upstream facts for imported types and functions.
 	ctx.importFacts()
 
-	// Phase 2: Infer guards from observations (skip imported types).
+	// Phase 1.6: Link callbacks passed to lock wrappers into the call graph.
+	ctx.addLockedCallbackCallSites()
+
+	// Phase 2: Infer guards from observations (skip imported types and globals).
 	ctx.inferGuards()
+	ctx.inferGlobalGuards()
 
 	// Phase 2.5: Derive per-function lock requirements from observations + guards.
 	ctx.deriveInitialRequirements()
+	ctx.deriveGlobalRequirements()
 
 	// Phase 3: Propagate requirements and acquisitions through call graph.
 	ctx.propagateRequirements()
@@ -164,29 +327,76 @@ func run(pass *analysis.Pass) (any, error) {
 	// Phase 3.5: Detect concurrent entrypoints and compute reachability.
 	ctx.computeConcurrentContext()
 
+	// Phase 3.6: Detect locks unlocked in a goroutine spawned by the locker (C12).
+	ctx.detectCrossGoroutineUnlocks()
+
 	// Phase 3.7: Collect interprocedural lock-order edges and detect cycles.
-	ctx.collectInterproceduralLockOrderEdges()
-	ctx.detectAndReportLockOrderCycles()
+	if ctx.checkEnabled("C3") {
+		ctx.collectInterproceduralLockOrderEdges()
+		ctx.detectAndReportLockOrderCycles()
+	}
 
 	// Phase 3.8: Detect acquire helpers and check their callers (C13).
 	ctx.computeReturnsHolding()
-	ctx.checkCallersOfAcquireHelpers()
+	if ctx.checkEnabled("C13") {
+		ctx.checkCallersOfAcquireHelpers()
+	}
 
 	// Phase 3.9: Report lock leaks (C5), suppressing acquire helpers.
-	ctx.reportDeferredLockLeaks()
+	if ctx.checkEnabled("C5") {
+		ctx.reportDeferredLockLeaks()
+	}
 
 	// Phase 3.9.3: Report unlock-of-unlocked (C4), suppressing acquire helper callers.
-	ctx.reportDeferredUnlockOfUnlocked()
+	if ctx.checkEnabled("C4") {
+		ctx.reportDeferredUnlockOfUnlocked()
+	}
+
+	// Phase 3.9.4: Report cross-goroutine unlocks (C12).
+	if ctx.checkEnabled("C12") {
+		ctx.reportCrossGoroutineUnlocks()
+	}
 
-	// Phase 4: Check violations (direct + interprocedural).
+	// Phase 3.9.5: Report goroutines spawned while holding a lock (C8).
+	if ctx.checkEnabled("C8") {
+		ctx.reportDeferredGoroutineSpawns()
+	}
+
+	// Phase 3.9.7: Report locks held across blocking operations (C9).
+	if ctx.checkEnabled("C9") {
+		ctx.reportDeferredBlockingOps()
+		ctx.checkBlockingCallsUnderLock()
+	}
+
+	// Phase 3.9.8: Report function bodies violating their lock contracts (C15).
+	if ctx.checkEnabled("C15") {
+		ctx.reportContractViolations()
+	}
+
+	// Phase 4: Check violations (direct + interprocedural). These phases
+	// report several checks; disabled ones are dropped by ctx.report.
 	ctx.checkViolations()
+	ctx.checkGlobalViolations()
 	ctx.checkInterproceduralViolations()
 
+	// Phase 4.4: Check plain accesses to atomically accessed fields (C16).
+	if ctx.checkEnabled("C16") {
+		ctx.checkMixedAtomicAccess()
+	}
+
 	// Phase 4.5: Check exported guarded fields (C14, local types only).
-	ctx.checkExportedGuardedFields()
+	if ctx.checkEnabled("C14") {
+		ctx.checkExportedGuardedFields()
+	}
+
+	// Phase 4.6: Check mutex copies (C10).
+	if ctx.checkEnabled("C10") {
+		ctx.checkMutexCopies()
+	}
+
+	// Phase 4.7: Report suppression directives that suppressed nothing.
+	ctx.reportUnusedSuppressions()
 
 	// Phase 5: Export facts for downstream packages.
 	ctx.exportFacts()
-
-	return nil, nil
 }
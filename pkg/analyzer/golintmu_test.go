@@ -1,21 +1,28 @@
 package analyzer_test
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/akerouanton/golintmu/pkg/analyzer"
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/packages"
 )
 
 // singlePkgAnalyzer wraps the real analyzer without FactTypes. This prevents
 // fact export in single-package tests, avoiding the need for fact expectations
 // in every test file. Cross-package tests use the real Analyzer which has FactTypes.
 var singlePkgAnalyzer = &analysis.Analyzer{
-	Name:     analyzer.Analyzer.Name,
-	Doc:      analyzer.Analyzer.Doc,
-	Run:      analyzer.Analyzer.Run,
-	Requires: analyzer.Analyzer.Requires,
+	Name:       analyzer.Analyzer.Name,
+	Doc:        analyzer.Analyzer.Doc,
+	Run:        analyzer.Analyzer.Run,
+	Requires:   analyzer.Analyzer.Requires,
+	ResultType: analyzer.Analyzer.ResultType,
 }
 
 func TestBasic(t *testing.T) {
@@ -113,6 +120,444 @@ func TestNestedValueType(t *testing.T) {
 	analysistest.Run(t, testdata, singlePkgAnalyzer, "nested_value_type")
 }
 
+func TestGoroutineSpawn(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "goroutine_spawn")
+}
+
+func TestGoroutineSpawnSeverity(t *testing.T) {
+	testdata := analysistest.TestData()
+	spawnSeverities := func() (reacquire, plain []string) {
+		results := analysistest.Run(t, testdata, singlePkgAnalyzer, "goroutine_spawn")
+		findings, _ := results[0].Result.([]analyzer.Finding)
+		for _, f := range findings {
+			switch {
+			case f.Check != "C8":
+			case strings.Contains(f.Message, "also locks"):
+				reacquire = append(reacquire, f.Severity)
+			default:
+				plain = append(plain, f.Severity)
+			}
+		}
+		if len(reacquire) == 0 || len(plain) == 0 {
+			t.Fatalf("got %d reacquire and %d plain C8 findings, want some of each", len(reacquire), len(plain))
+		}
+		return reacquire, plain
+	}
+
+	reacquire, plain := spawnSeverities()
+	for _, sev := range reacquire {
+		if sev != analyzer.SeverityError {
+			t.Errorf("reacquire finding has severity %q, want %q", sev, analyzer.SeverityError)
+		}
+	}
+	for _, sev := range plain {
+		if sev != analyzer.SeverityWarning {
+			t.Errorf("plain spawn finding has severity %q, want %q", sev, analyzer.SeverityWarning)
+		}
+	}
+
+	// An explicit -severity override applies to the escalated finding too.
+	if err := analyzer.Analyzer.Flags.Set("severity", "C8=note"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := analyzer.Analyzer.Flags.Set("severity", ""); err != nil {
+			t.Fatal(err)
+		}
+	})
+	reacquire, _ = spawnSeverities()
+	for _, sev := range reacquire {
+		if sev != analyzer.SeverityNote {
+			t.Errorf("reacquire finding has severity %q under -severity C8=note, want %q", sev, analyzer.SeverityNote)
+		}
+	}
+}
+
+func TestBlockingOps(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "blocking_ops")
+}
+
+func TestMutexCopy(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "mutex_copy")
+}
+
+func TestCrossGoroutineUnlock(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "cross_goroutine_unlock")
+}
+
+func TestOnce(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "once")
+}
+
+func TestAtomicAccess(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "atomic_access")
+}
+
+func TestMixedAtomicSelection(t *testing.T) {
+	testdata := analysistest.TestData()
+	if err := analyzer.Analyzer.Flags.Set("checks", "-C16"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := analyzer.Analyzer.Flags.Set("checks", ""); err != nil {
+			t.Fatal(err)
+		}
+	})
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "mixed_atomic_selection")
+}
+
+func TestGlobals(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "globals")
+}
+
+func TestLockWrappers(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "lock_wrappers")
+}
+
+func TestSuggestedFixes(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, singlePkgAnalyzer, "suggested_fixes")
+}
+
+func TestStructuredOutput(t *testing.T) {
+	testdata := analysistest.TestData()
+	if err := analyzer.Analyzer.Flags.Set("verbose", "true"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := analyzer.Analyzer.Flags.Set("verbose", "false"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	results := analysistest.Run(t, testdata, singlePkgAnalyzer, "structured_output")
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	findings, ok := results[0].Result.([]analyzer.Finding)
+	if !ok {
+		t.Fatalf("result has type %T, want []analyzer.Finding", results[0].Result)
+	}
+
+	byCheck := make(map[string][]analyzer.Finding)
+	for _, f := range findings {
+		if f.Pos.Line == 0 || filepath.Base(f.Pos.File) != "structured_output.go" {
+			t.Errorf("finding %q has position %+v", f.Message, f.Pos)
+		}
+		byCheck[f.Check] = append(byCheck[f.Check], f)
+	}
+	if got := len(byCheck["C1"]); got != 3 {
+		t.Errorf("got %d C1 findings, want 3", got)
+	}
+
+	for _, f := range byCheck["C1"] {
+		switch {
+		case f.Field == "count":
+			if f.Type != "structured_output.Counter" || f.Mutex != "Counter.mu" || f.Access != "read" || f.Mode != "shared" || f.Func != "(*Counter).Peek" {
+				t.Errorf("unexpected field finding: %+v", f)
+			}
+		case f.Variable != "":
+			if f.Variable != "structured_output.hits" || f.Mutex != "hitsMu" || f.Access != "read" {
+				t.Errorf("unexpected global finding: %+v", f)
+			}
+		default:
+			if f.Mutex != "Cache.mu" || len(f.Provenance) != 1 || len(f.Provenance[0]) != 2 {
+				t.Fatalf("unexpected call-site finding: %+v", f)
+			}
+			if strings.Contains(f.Message, "\n") {
+				t.Errorf("message includes provenance lines: %q", f.Message)
+			}
+			calls, access := f.Provenance[0][0], f.Provenance[0][1]
+			if calls.Func != "purge" || calls.Kind != "calls" || calls.Target != "reset" {
+				t.Errorf("unexpected first provenance step: %+v", calls)
+			}
+			if access.Func != "reset" || access.Kind != "accesses" || access.Target != "Cache.items" {
+				t.Errorf("unexpected second provenance step: %+v", access)
+			}
+		}
+	}
+
+	if leaks := byCheck["C5"]; len(leaks) != 1 || len(leaks[0].Related) != 1 || leaks[0].Related[0].Message != "Counter.mu locked here" {
+		t.Errorf("unexpected C5 findings: %+v", leaks)
+	}
+	if cycles := byCheck["C3"]; len(cycles) != 1 || len(cycles[0].Related) != 2 {
+		t.Errorf("unexpected C3 findings: %+v", cycles)
+	}
+}
+
+func TestCheckSelection(t *testing.T) {
+	testdata := analysistest.TestData()
+	for name, value := range map[string]string{"checks": "-C5,-C14", "severity": "C1=warning"} {
+		if err := analyzer.Analyzer.Flags.Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			if err := analyzer.Analyzer.Flags.Set(name, ""); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+	results := analysistest.Run(t, testdata, singlePkgAnalyzer, "check_selection")
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	findings, _ := results[0].Result.([]analyzer.Finding)
+	if len(findings) != 1 || findings[0].Check != "C1" || findings[0].Severity != analyzer.SeverityWarning {
+		t.Errorf("unexpected findings: %+v", findings)
+	}
+	if got := analyzer.Severity("C14"); got != analyzer.SeverityWarning {
+		t.Errorf("Severity(C14) = %q, want catalog default %q", got, analyzer.SeverityWarning)
+	}
+}
+
+func TestCheckFlagValidation(t *testing.T) {
+	for _, tc := range []struct{ flag, value string }{
+		{"checks", "C1,C99"},
+		{"checks", "-C0"},
+		{"severity", "C1"},
+		{"severity", "C1=fatal"},
+		{"severity", "C0=error"},
+	} {
+		if err := analyzer.Analyzer.Flags.Set(tc.flag, tc.value); err == nil {
+			t.Errorf("-%s=%s: expected an error", tc.flag, tc.value)
+		}
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "config_file", "config_file/excluded")
+}
+
+// errorRecorder is an analysistest.Testing that collects errors instead of
+// failing the test.
+type errorRecorder struct{ errs []string }
+
+func (r *errorRecorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func TestConfigReload(t *testing.T) {
+	dir := t.TempDir()
+	pkgDir := filepath.Join(dir, "src", "reload")
+	if err := os.MkdirAll(pkgDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src := `package reload
+
+import "sync"
+
+type Counter struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (c *Counter) Inc() {
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+}
+
+func (c *Counter) Peek() int {
+	return c.n // want ` + "`field Counter\\.n is accessed without holding Counter\\.mu`" + `
+}
+`
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(pkgDir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("reload.go", src)
+
+	// C1 disabled: the expected diagnostic is missing.
+	write("golintmu.json", `{"checks": ["-C1"]}`)
+	rec := &errorRecorder{}
+	results := analysistest.Run(rec, dir, singlePkgAnalyzer, "reload")
+	if findings, _ := results[0].Result.([]analyzer.Finding); len(findings) != 0 {
+		t.Fatalf("unexpected findings with C1 disabled: %+v", findings)
+	}
+
+	// The edited configuration is read again by the same process.
+	write("golintmu.json", `{}`)
+	analysistest.Run(t, dir, singlePkgAnalyzer, "reload")
+}
+
+func TestConfigIsolation(t *testing.T) {
+	testdata := analysistest.TestData()
+	// Loading the configuration of config_file must not make its mutex types
+	// mutexes in packages analyzed later by the same process.
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "config_file")
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "config_isolation")
+}
+
+func TestBaseline(t *testing.T) {
+	testdata := analysistest.TestData()
+	c1 := analyzer.Finding{Check: "C1", Package: "baseline", Func: "(*Counter).Peek", Type: "baseline.Counter", Field: "count", Mutex: "Counter.mu"}
+	c5 := analyzer.Finding{Check: "C5", Package: "baseline", Func: "(*Counter).Reset", Type: "baseline.Counter", Mutex: "Counter.mu"}
+	var buf strings.Builder
+	if err := analyzer.NewBaseline([]analyzer.Finding{c1, c5}).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "golintmu.baseline")
+	if err := os.WriteFile(path, []byte(buf.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := analyzer.Analyzer.Flags.Set("baseline", path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := analyzer.Analyzer.Flags.Set("baseline", ""); err != nil {
+			t.Fatal(err)
+		}
+	})
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "baseline")
+}
+
+func TestSuppressionDirectives(t *testing.T) {
+	testdata := analysistest.TestData()
+	if err := analyzer.Analyzer.Flags.Set("require-reason", "true"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := analyzer.Analyzer.Flags.Set("require-reason", "false"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "suppression_directives")
+}
+
+func TestUnusedSuppressions(t *testing.T) {
+	testdata := analysistest.TestData()
+	if err := analyzer.Analyzer.Flags.Set("report-unused-suppressions", "true"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := analyzer.Analyzer.Flags.Set("report-unused-suppressions", "false"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	analysistest.RunWithSuggestedFixes(t, testdata, singlePkgAnalyzer, "unused_suppressions")
+}
+
+func TestLockContracts(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "lock_contracts")
+}
+
+func TestFieldAnnotations(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "field_annotations")
+}
+
+func TestServiceEntrypoints(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "service_entrypoints")
+}
+
+func TestHTTPEntrypoints(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "http_entrypoints")
+}
+
+func TestFrameworkEntrypoints(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "framework_entrypoints")
+}
+
+func TestCallbackEntrypoints(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "callback_entrypoints")
+}
+
+func TestExportedEntrypoints(t *testing.T) {
+	testdata := analysistest.TestData()
+	if err := analyzer.Analyzer.Flags.Set("exported-entrypoints", "true"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := analyzer.Analyzer.Flags.Set("exported-entrypoints", "false"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	analysistest.Run(t, testdata, singlePkgAnalyzer, "exported_entrypoints")
+}
+
+func TestConcurrentContextAcrossPackages(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, analyzer.Analyzer, "concurrent_context/pool", "concurrent_context/app")
+}
+
+// TestWholeProgram checks the findings of RunWholeProgram against the want
+// comments of the packages: the guard of store.Stats is only inferred from the
+// accesses of package app, which the go/analysis driver cannot do.
+func TestWholeProgram(t *testing.T) {
+	testdata := analysistest.TestData()
+	cfg := &packages.Config{
+		Mode: packages.LoadAllSyntax,
+		Dir:  testdata,
+		Env:  append(os.Environ(), "GOPATH="+testdata, "GO111MODULE=off", "GOPROXY=off"),
+	}
+	pkgs, err := packages.Load(cfg, "whole_program/legacy", "whole_program/store", "whole_program/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("errors while loading packages")
+	}
+	findings, err := analyzer.RunWholeProgram(pkgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Expectations, by "file:line", from the // want comments.
+	wantRe := regexp.MustCompile("`([^`]*)`")
+	wants := make(map[string][]*regexp.Regexp)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			for _, cg := range file.Comments {
+				for _, c := range cg.List {
+					text, ok := strings.CutPrefix(c.Text, "// want ")
+					if !ok {
+						continue
+					}
+					pos := pkg.Fset.Position(c.Pos())
+					key := fmt.Sprintf("%s:%d", pos.Filename, pos.Line)
+					for _, m := range wantRe.FindAllStringSubmatch(text, -1) {
+						wants[key] = append(wants[key], regexp.MustCompile(m[1]))
+					}
+				}
+			}
+		}
+	}
+
+	for _, f := range findings {
+		key := fmt.Sprintf("%s:%d", f.Pos.File, f.Pos.Line)
+		matched := false
+		for i, re := range wants[key] {
+			if re.MatchString(f.Message) {
+				wants[key] = append(wants[key][:i], wants[key][i+1:]...)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			t.Errorf("%s: unexpected finding: %s", key, f.Message)
+		}
+	}
+	for key, res := range wants {
+		for _, re := range res {
+			t.Errorf("%s: no finding matching %q", key, re)
+		}
+	}
+}
+
 func TestInterproceduralVerbose(t *testing.T) {
 	testdata := analysistest.TestData()
 	if err := analyzer.Analyzer.Flags.Set("verbose", "true"); err != nil {
@@ -11,14 +11,34 @@ import (
 func (ctx *passContext) inferGuards() {
 	for key, observations := range ctx.observations {
 		// Skip imported types — their guards came from facts.
-		if key.StructType.Obj().Pkg() != ctx.pass.Pkg {
+		if !ctx.isLocal(key.StructType.Obj().Pkg()) {
 			continue
 		}
 
-		// Filter out constructor observations.
+		// Fields of sync/atomic types are self-synchronized.
+		if isAtomicField(key) {
+			continue
+		}
+
+		// Excluded by the configuration.
+		if ctx.fieldExcluded(key) {
+			continue
+		}
+
+		// Annotated with //mu:guardedby or //mu:unguarded: see
+		// applyFieldGuardAnnotations.
+		if _, ok := ctx.annotations.fieldGuards[key]; ok {
+			continue
+		}
+
+		// Filter out constructor and sync.Once callback observations, and
+		// atomic accesses (self-synchronized, checked by checkMixedAtomicAccess).
 		var filtered []observation
 		for _, obs := range observations {
-			if isConstructorLike(obs.Func, key.StructType) {
+			if ctx.isInitializationContext(obs.Func, key.StructType) {
+				continue
+			}
+			if obs.IsAtomic {
 				continue
 			}
 			filtered = append(filtered, obs)
@@ -27,7 +47,8 @@ func (ctx *passContext) inferGuards() {
 			continue
 		}
 
-		// Check if the field is immutable (all writes in constructors).
+		// Check if the field is immutable (all writes in constructors or
+		// sync.Once callbacks).
 		if isImmutableField(filtered) {
 			continue
 		}
@@ -38,11 +59,47 @@ func (ctx *passContext) inferGuards() {
 			ctx.guards[key] = guard
 		}
 	}
+	ctx.applyFieldGuardAnnotations()
+}
+
+// applyFieldGuardAnnotations sets the guards of fields annotated with
+// //mu:guardedby, whether observed in this package or not, so that they are
+// exported. Fields annotated with //mu:unguarded get no guard.
+// NeedsExclusive is true when the field is written outside initialization.
+func (ctx *passContext) applyFieldGuardAnnotations() {
+	for key, mutexFieldIndex := range ctx.annotations.fieldGuards {
+		if mutexFieldIndex < 0 || ctx.fieldExcluded(key) {
+			continue
+		}
+		needsExclusive := false
+		for _, obs := range ctx.observations[key] {
+			if !obs.IsRead && !obs.IsAtomic && !ctx.isInitializationContext(obs.Func, key.StructType) {
+				needsExclusive = true
+				break
+			}
+		}
+		ctx.guards[key] = guardInfo{MutexFieldIndex: mutexFieldIndex, NeedsExclusive: needsExclusive}
+	}
+}
+
+// isUnguardedField reports whether the field is annotated with //mu:unguarded.
+func (ctx *passContext) isUnguardedField(key fieldKey) bool {
+	guard, ok := ctx.annotations.fieldGuards[key]
+	return ok && guard < 0
+}
+
+// isAtomicField returns true if the field has a sync/atomic type.
+func isAtomicField(key fieldKey) bool {
+	st, ok := key.StructType.Underlying().(*types.Struct)
+	if !ok || key.FieldIndex >= st.NumFields() {
+		return false
+	}
+	return isAtomicType(st.Field(key.FieldIndex).Type())
 }
 
 // isConstructorLike returns true if the function looks like a constructor for
 // the given struct type.
-func isConstructorLike(fn *ssa.Function, structType *types.Named) bool {
+func (ctx *passContext) isConstructorLike(fn *ssa.Function, structType *types.Named) bool {
 	// SSA renames user-written init() functions to init#1, init#2, etc.
 	if fn.Name() == "init" || strings.HasPrefix(fn.Name(), "init#") {
 		return true
@@ -52,11 +109,13 @@ func isConstructorLike(fn *ssa.Function, structType *types.Named) bool {
 		return true
 	}
 
-	// Name-based heuristic: only match New/Make/Create prefixes when the
-	// struct name is part of the function name (e.g. NewConfig for Config).
+	// Name-based heuristic: only match New/Make/Create prefixes (and the
+	// configured ones) when the struct name is part of the function name
+	// (e.g. NewConfig for Config).
 	structName := structType.Obj().Name()
 	name := fn.Name()
-	for _, prefix := range []string{"New", "Make", "Create"} {
+	prefixes := append([]string{"New", "Make", "Create"}, ctx.configOf(ctx.pkgOf(fn)).ConstructorPrefixes...)
+	for _, prefix := range prefixes {
 		if strings.HasPrefix(name, prefix) && strings.Contains(name, structName) {
 			return true
 		}
@@ -83,7 +142,8 @@ func returnsStructType(fn *ssa.Function, structType *types.Named) bool {
 	return false
 }
 
-// isImmutableField returns true if all non-constructor observations are reads.
+// isImmutableField returns true if all observations outside constructors and
+// sync.Once callbacks are reads.
 func isImmutableField(filteredObs []observation) bool {
 	for _, obs := range filteredObs {
 		if !obs.IsRead {
@@ -7,10 +7,13 @@ import (
 	"golang.org/x/tools/go/ssa"
 )
 
-// mutexFieldKey uniquely identifies a mutex field across the package.
+// mutexFieldKey uniquely identifies a mutex across the package: either a
+// mutex field of a struct type, or a package-level mutex (Global set,
+// StructType nil).
 type mutexFieldKey struct {
 	StructType *types.Named
 	FieldIndex int
+	Global     *types.Var
 }
 
 // heldMutexRef is the normalized cross-function representation of a held mutex,
@@ -23,8 +26,10 @@ type heldMutexRef struct {
 // requirementOrigin records why a function requires a specific lock.
 // Either a direct field access (AccessPos valid) or a transitive call (ViaCallee non-nil).
 type requirementOrigin struct {
-	// For direct requirements: the field access that triggered it.
+	// For direct requirements: the field (or package-level variable) access
+	// that triggered it.
 	FieldKey  fieldKey
+	Global    *types.Var
 	AccessPos token.Pos
 	IsRead    bool
 
@@ -40,17 +45,23 @@ type callSiteRecord struct {
 	Callee           *ssa.Function
 	Pos              token.Pos
 	HeldByStructType map[*types.Named][]heldMutexRef // normalized lock state: struct type → held mutex refs
-	ReceiverValue    ssa.Value                        // SSA value of the callee's receiver at the call site (nil for non-method calls)
+	HeldGlobals      []heldGlobalMutex               // package-level mutexes held at the call site
+	Args             []ssa.Value                     // call arguments, including the receiver (used to resolve callbacks passed to lock wrappers)
+	ReceiverValue    ssa.Value                       // SSA value of the callee's receiver at the call site (nil for non-method calls)
 }
 
 // funcLockFacts tracks lock requirements and acquisitions for a function.
 type funcLockFacts struct {
-	Requires           map[mutexFieldKey]bool                    // locks callers must hold
-	RequiresOrigin     map[mutexFieldKey][]requirementOrigin     // why each requirement exists (verbose mode)
-	Acquires           map[mutexFieldKey]bool                    // locks this function directly acquires
-	AcquiresTransitive map[mutexFieldKey]bool                    // direct + transitive acquisitions (via callees)
-	ReturnsHolding     map[mutexFieldKey]bool                    // locks held at ALL return points
-	Releases           map[mutexFieldKey]bool                    // locks explicitly unlocked in this function
+	Requires           map[mutexFieldKey]bool                // locks callers must hold
+	RequiresOrigin     map[mutexFieldKey][]requirementOrigin // why each requirement exists (verbose mode)
+	Acquires           map[mutexFieldKey]bool                // locks this function directly acquires
+	AcquiresTransitive map[mutexFieldKey]bool                // direct + transitive acquisitions (via callees)
+	ReturnsHolding     map[mutexFieldKey]bool                // locks held at ALL return points
+	Releases           map[mutexFieldKey]bool                // locks explicitly unlocked in this function
+	ReleasesHeld       map[mutexFieldKey]bool                // locks held by callers that this function releases (//mu:releases)
+	MayBlock           bool                                  // function (or a callee) performs a blocking operation
+	MayBlockOrigin     *blockingOrigin                       // why the function may block (verbose mode)
+	LockedCallbacks    map[int]map[mutexFieldKey]bool        // param index → locks held (value: exclusive) when the func-typed param is invoked
 }
 
 // getOrCreateFuncFacts returns the funcLockFacts for a function, creating it if needed.
@@ -64,6 +75,8 @@ func (ctx *passContext) getOrCreateFuncFacts(fn *ssa.Function) *funcLockFacts {
 		AcquiresTransitive: make(map[mutexFieldKey]bool),
 		ReturnsHolding:     make(map[mutexFieldKey]bool),
 		Releases:           make(map[mutexFieldKey]bool),
+		ReleasesHeld:       make(map[mutexFieldKey]bool),
+		LockedCallbacks:    make(map[int]map[mutexFieldKey]bool),
 	}
 	if ctx.verbose {
 		facts.RequiresOrigin = make(map[mutexFieldKey][]requirementOrigin)
@@ -104,7 +117,14 @@ func normalizeLockState(ls *lockState) map[*types.Named][]heldMutexRef {
 func (ctx *passContext) deriveInitialRequirements() {
 	for key, guard := range ctx.guards {
 		for _, obs := range ctx.observations[key] {
-			if isConstructorLike(obs.Func, key.StructType) {
+			if ctx.isInitializationContext(obs.Func, key.StructType) {
+				continue
+			}
+			if obs.IsAtomic {
+				continue
+			}
+			// A contract replaces inference.
+			if ctx.contract(obs.Func) != nil {
 				continue
 			}
 
@@ -163,9 +183,12 @@ func (ctx *passContext) propagateRequirements() {
 					if callerHoldsMutex(cs, mfk) {
 						continue // caller satisfies this requirement
 					}
-					if isPrePublicationConstructorCall(cs) {
+					if ctx.isPrePublicationConstructorCall(cs) {
 						continue // pre-publication: struct not shared yet
 					}
+					if ctx.contract(cs.Caller) != nil {
+						continue // the caller's contract replaces inference
+					}
 					// Propagate requirement to caller.
 					callerFacts := ctx.getOrCreateFuncFacts(cs.Caller)
 					if !callerFacts.Requires[mfk] {
@@ -219,6 +242,9 @@ func (ctx *passContext) propagateRequirements() {
 			}
 		}
 	}
+
+	// Propagate MayBlock upward for C9 call-site detection.
+	ctx.propagateMayBlock()
 }
 
 // isPrePublicationConstructorCall returns true if the call site is a constructor
@@ -232,7 +258,7 @@ func (ctx *passContext) propagateRequirements() {
 // cases (publication in a different branch than the call), the comparison is
 // conservative: it may treat some pre-publication calls as post-publication
 // (still reporting violations), but never the reverse.
-func isPrePublicationConstructorCall(cs callSiteRecord) bool {
+func (ctx *passContext) isPrePublicationConstructorCall(cs callSiteRecord) bool {
 	callee := cs.Callee
 	if callee.Signature.Recv() == nil {
 		return false // not a method call
@@ -250,7 +276,7 @@ func isPrePublicationConstructorCall(cs callSiteRecord) bool {
 	}
 
 	// Caller must be constructor-like for the receiver's struct type.
-	if !isConstructorLike(cs.Caller, named) {
+	if !ctx.isConstructorLike(cs.Caller, named) {
 		return false
 	}
 
@@ -301,6 +327,14 @@ func isLocalAlloc(v ssa.Value) bool {
 // callerHoldsMutex returns true if the call site record indicates the caller
 // holds the specified mutex at the call point (mode-agnostic — any lock satisfies).
 func callerHoldsMutex(cs callSiteRecord, mfk mutexFieldKey) bool {
+	if mfk.Global != nil {
+		for _, hm := range cs.HeldGlobals {
+			if hm.Mutex == mfk.Global {
+				return true
+			}
+		}
+		return false
+	}
 	heldRefs, ok := cs.HeldByStructType[mfk.StructType]
 	if !ok {
 		return false
@@ -313,6 +347,21 @@ func callerHoldsMutex(cs callSiteRecord, mfk mutexFieldKey) bool {
 	return false
 }
 
+// heldMutexKeys returns the mutexes held by the caller at a call site, struct
+// fields and package-level mutexes alike.
+func heldMutexKeys(cs callSiteRecord) []mutexFieldKey {
+	var keys []mutexFieldKey
+	for structType, heldRefs := range cs.HeldByStructType {
+		for _, hr := range heldRefs {
+			keys = append(keys, mutexFieldKey{StructType: structType, FieldIndex: hr.FieldIndex})
+		}
+	}
+	for _, hm := range cs.HeldGlobals {
+		keys = append(keys, mutexFieldKey{Global: hm.Mutex})
+	}
+	return keys
+}
+
 // collectInterproceduralLockOrderEdges adds edges to the lock-order graph for
 // call sites where the caller holds locks and the callee acquires locks transitively.
 func (ctx *passContext) collectInterproceduralLockOrderEdges() {
@@ -325,24 +374,20 @@ func (ctx *passContext) collectInterproceduralLockOrderEdges() {
 			continue
 		}
 
-		// For each lock held by the caller at this call site...
-		for structType, heldRefs := range cs.HeldByStructType {
-			for _, hr := range heldRefs {
-				heldKey := mutexFieldKey{StructType: structType, FieldIndex: hr.FieldIndex}
-
-				// ...add an edge to each lock the callee transitively acquires.
-				// Skip same-key edges — those are interprocedural double-locks (C2).
-				for acquiredKey := range calleeFacts.AcquiresTransitive {
-					if heldKey == acquiredKey {
-						continue
-					}
-					ctx.lockOrderGraph.addEdge(lockOrderEdge{
-						From: heldKey,
-						To:   acquiredKey,
-						Pos:  cs.Pos,
-						Fn:   cs.Caller,
-					})
+		// For each lock held by the caller at this call site, add an edge to
+		// each lock the callee transitively acquires. Skip same-key edges —
+		// those are interprocedural double-locks (C2).
+		for _, heldKey := range heldMutexKeys(cs) {
+			for acquiredKey := range calleeFacts.AcquiresTransitive {
+				if heldKey == acquiredKey {
+					continue
 				}
+				ctx.lockOrderGraph.addEdge(lockOrderEdge{
+					From: heldKey,
+					To:   acquiredKey,
+					Pos:  cs.Pos,
+					Fn:   cs.Caller,
+				})
 			}
 		}
 	}
@@ -11,7 +11,8 @@ import (
 type lockRefKind int
 
 const (
-	fieldLock lockRefKind = iota // mutex is a field of a struct
+	fieldLock  lockRefKind = iota // mutex is a field of a struct
+	globalLock                    // mutex is a package-level variable
 )
 
 // lockRef identifies a specific lock instance. Two lockRefs are equal when they
@@ -21,8 +22,8 @@ const (
 // that multiple loads from the same heap cell resolve to the same lockRef.
 type lockRef struct {
 	kind       lockRefKind
-	base       ssa.Value // canonical SSA value for the struct containing the mutex
-	fieldIndex int       // field index within the struct
+	base       ssa.Value // canonical SSA value for the struct containing the mutex (*ssa.Global for globalLock)
+	fieldIndex int       // field index within the struct (-1 for globalLock)
 }
 
 // heldLock is a lockRef with an exclusive flag.
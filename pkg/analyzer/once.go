@@ -0,0 +1,135 @@
+package analyzer
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// detectOnceCallbacks finds functions passed to (*sync.Once).Do and to
+// sync.OnceFunc / sync.OnceValue / sync.OnceValues. Their bodies run exactly
+// once, synchronized by the Once, so field accesses inside them are treated
+// like constructor accesses: excluded from guard inference and violation
+// checking. A named function or method (once.Do(s.init)) can also be called
+// outside the Once: it is a callback only when the package references it
+// nowhere else.
+func (ctx *passContext) detectOnceCallbacks() {
+	onceArgs := make(map[ssa.Instruction]ssa.Value) // Once call → its callback argument
+	for _, fn := range ctx.srcFuncs {
+		for _, block := range fn.Blocks {
+			for _, instr := range block.Instrs {
+				call, ok := instr.(*ssa.Call)
+				if !ok {
+					continue
+				}
+				common := call.Common()
+				callee := common.StaticCallee()
+				if callee == nil || len(common.Args) == 0 {
+					continue
+				}
+				if !isOnceDo(callee) && !isOnceWrapper(callee) {
+					continue
+				}
+				// The callback is the last argument (after the receiver for Once.Do).
+				arg := common.Args[len(common.Args)-1]
+				if target := ctx.resolveCallbackFunc(arg); target != nil {
+					ctx.onceFuncs[target] = true
+					onceArgs[call] = arg
+				}
+			}
+		}
+	}
+
+	for _, fn := range ctx.srcFuncs {
+		for _, block := range fn.Blocks {
+			for _, instr := range block.Instrs {
+				if mc, ok := instr.(*ssa.MakeClosure); ok && isBoundMethodWrapper(mc.Fn) {
+					continue // referenced through the closure value
+				}
+				for _, op := range instr.Operands(nil) {
+					if *op == nil || *op == onceArgs[instr] {
+						continue
+					}
+					target := ctx.resolveCallbackFunc(*op)
+					if target != nil && target.Parent() == nil {
+						delete(ctx.onceFuncs, target)
+					}
+				}
+			}
+		}
+	}
+}
+
+// isBoundMethodWrapper reports whether v is the synthetic function of a
+// bound method value (s.init).
+func isBoundMethodWrapper(v ssa.Value) bool {
+	fn, ok := v.(*ssa.Function)
+	return ok && fn.Synthetic != "" && len(fn.FreeVars) > 0
+}
+
+// resolveCallbackFunc resolves a function-valued argument to the SSA function
+// it invokes: a function reference, a closure, or a bound method value (in
+// which case the underlying method is returned).
+func (ctx *passContext) resolveCallbackFunc(v ssa.Value) *ssa.Function {
+	var fn *ssa.Function
+	switch val := v.(type) {
+	case *ssa.Function:
+		fn = val
+	case *ssa.MakeClosure:
+		fn, _ = val.Fn.(*ssa.Function)
+	}
+	if fn == nil {
+		return nil
+	}
+	// Bound method values (s.init) are wrapped in a synthetic closure.
+	if fn.Synthetic != "" {
+		if obj, ok := fn.Object().(*types.Func); ok {
+			if method := ctx.ssaPkg.Prog.FuncValue(obj); method != nil {
+				return method
+			}
+		}
+	}
+	return fn
+}
+
+// isOnceDo returns true if fn is (*sync.Once).Do.
+func isOnceDo(fn *ssa.Function) bool {
+	recv := fn.Signature.Recv()
+	if recv == nil || fn.Name() != "Do" {
+		return false
+	}
+	ptr, ok := recv.Type().(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "sync" && obj.Name() == "Once"
+}
+
+// isOnceWrapper returns true if fn is sync.OnceFunc, sync.OnceValue or
+// sync.OnceValues (including generic instantiations).
+func isOnceWrapper(fn *ssa.Function) bool {
+	if fn.Signature.Recv() != nil {
+		return false
+	}
+	obj, ok := fn.Object().(*types.Func)
+	if !ok || obj.Pkg() == nil || obj.Pkg().Path() != "sync" {
+		return false
+	}
+	switch obj.Name() {
+	case "OnceFunc", "OnceValue", "OnceValues":
+		return true
+	}
+	return false
+}
+
+// isInitializationContext returns true if field accesses in fn should be
+// treated as initialization of structType: constructor-like functions and
+// sync.Once callbacks.
+func (ctx *passContext) isInitializationContext(fn *ssa.Function, structType *types.Named) bool {
+	return ctx.isConstructorLike(fn, structType) || ctx.onceFuncs[fn]
+}
@@ -2,10 +2,14 @@ package analyzer
 
 import (
 	"fmt"
+	"go/ast"
 	"go/token"
 	"go/types"
 	"path/filepath"
+	"sort"
+	"strings"
 
+	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/ssa"
 )
 
@@ -16,7 +20,10 @@ func (ctx *passContext) checkViolations() {
 	for key, guard := range ctx.guards {
 		observations := ctx.observations[key]
 		for _, obs := range observations {
-			if isConstructorLike(obs.Func, key.StructType) {
+			if ctx.isInitializationContext(obs.Func, key.StructType) {
+				continue
+			}
+			if obs.IsAtomic {
 				continue
 			}
 
@@ -44,6 +51,11 @@ func (ctx *passContext) checkViolations() {
 				if !ctx.isConcurrent(obs.Func) {
 					continue
 				}
+				// Plain access to a field also accessed atomically: reported
+				// by checkMixedAtomicAccess instead.
+				if ctx.atomicFields[key] {
+					continue
+				}
 				// Suppress direct violation if this function has a requirement
 				// for this lock and has callers — the violation will be reported
 				// at the call sites instead.
@@ -75,7 +87,7 @@ func (ctx *passContext) shouldSuppressDirectViolation(fn *ssa.Function, mfk mute
 
 // reportViolation emits a diagnostic for a field access without the required lock.
 func (ctx *passContext) reportViolation(obs observation, key fieldKey, guard guardInfo) {
-	if ctx.isSuppressed(obs.Func, obs.Pos) {
+	if ctx.isSuppressed(obs.Func, obs.Pos, "C1") {
 		return
 	}
 	structName := key.StructType.Obj().Name()
@@ -88,18 +100,95 @@ func (ctx *passContext) reportViolation(obs observation, key fieldKey, guard gua
 	}
 	fieldName := st.Field(key.FieldIndex).Name()
 	mutexName := st.Field(guard.MutexFieldIndex).Name()
+	access, mode := accessKind(obs.IsRead)
+
+	ctx.reportWithFixes(obs.Pos, Finding{
+		Check: "C1",
+		Message: fmt.Sprintf("field %s.%s is accessed without holding %s.%s",
+			structName, fieldName, structName, mutexName),
+		Func:   ctx.funcName(obs.Func),
+		Type:   qualifiedTypeName(key.StructType),
+		Field:  fieldName,
+		Mutex:  structName + "." + mutexName,
+		Mode:   mode,
+		Access: access,
+	}, ctx.wrapInLockFix(obs.Func, obs.Pos, mutexFieldKey{StructType: key.StructType, FieldIndex: guard.MutexFieldIndex}, ctx.fieldLockExpr(key, mutexName)))
+}
 
-	msg := fmt.Sprintf("field %s.%s is accessed without holding %s.%s",
-		structName, fieldName, structName, mutexName)
+// reportGlobalViolation emits a diagnostic for a package-level variable
+// accessed without its inferred guard.
+func (ctx *passContext) reportGlobalViolation(obs globalObservation, v *types.Var, guard globalGuardInfo) {
+	if ctx.isSuppressed(obs.Func, obs.Pos, "C1") {
+		return
+	}
+	access, mode := accessKind(obs.IsRead)
+	lockExpr := func(ast.Stmt) string {
+		if guard.Mutex.Pkg() != ctx.pkgOf(obs.Func) {
+			return ""
+		}
+		return guard.Mutex.Name()
+	}
+	ctx.reportWithFixes(obs.Pos, Finding{
+		Check: "C1",
+		Message: fmt.Sprintf("global variable %s is accessed without holding %s",
+			ctx.globalVarName(obs.Func, v), ctx.globalVarName(obs.Func, guard.Mutex)),
+		Func:     ctx.funcName(obs.Func),
+		Variable: qualifiedVarName(v),
+		Mutex:    ctx.globalVarName(obs.Func, guard.Mutex),
+		Mode:     mode,
+		Access:   access,
+	}, ctx.wrapInLockFix(obs.Func, obs.Pos, mutexFieldKey{Global: guard.Mutex}, lockExpr))
+}
 
-	ctx.pass.Reportf(obs.Pos, "%s", msg)
+// reportGlobalWriteUnderSharedLock emits a diagnostic for writing a
+// package-level variable while its guard is only read-locked.
+func (ctx *passContext) reportGlobalWriteUnderSharedLock(obs globalObservation, v *types.Var, guard globalGuardInfo) {
+	if ctx.isSuppressed(obs.Func, obs.Pos, "C6") {
+		return
+	}
+	ctx.report(obs.Pos, Finding{
+		Check: "C6",
+		Message: fmt.Sprintf("global variable %s is written while %s is read-locked \u2014 use Lock() for write access",
+			ctx.globalVarName(obs.Func, v), ctx.globalVarName(obs.Func, guard.Mutex)),
+		Func:     ctx.funcName(obs.Func),
+		Variable: qualifiedVarName(v),
+		Mutex:    ctx.globalVarName(obs.Func, guard.Mutex),
+		Mode:     modeShared,
+		Access:   accessWrite,
+	})
+}
+
+// reportMixedAtomicAccess emits a diagnostic for a plain access to a field
+// that is accessed through sync/atomic elsewhere.
+func (ctx *passContext) reportMixedAtomicAccess(obs observation, key fieldKey, atomicPos token.Pos) {
+	if ctx.isSuppressed(obs.Func, obs.Pos, "C16") {
+		return
+	}
+	st, ok := key.StructType.Underlying().(*types.Struct)
+	if !ok || key.FieldIndex >= st.NumFields() {
+		return
+	}
+	structName := key.StructType.Obj().Name()
+	fieldName := st.Field(key.FieldIndex).Name()
+	pos := ctx.pass.Fset.Position(atomicPos)
+	access, _ := accessKind(obs.IsRead)
+
+	ctx.report(obs.Pos, Finding{
+		Check: "C16",
+		Message: fmt.Sprintf("field %s.%s is accessed atomically at %s:%d:%d but plainly here \u2014 use sync/atomic for every access",
+			structName, fieldName, filepath.Base(pos.Filename), pos.Line, pos.Column),
+		Func:   ctx.funcName(obs.Func),
+		Type:   qualifiedTypeName(key.StructType),
+		Field:  fieldName,
+		Access: access,
+	}, relatedAt(atomicPos, "%s.%s accessed atomically here", structName, fieldName))
 }
 
 // reportWriteUnderSharedLock emits a diagnostic for writing a field while only
 // holding a read lock (RLock) — this is a data race since RLock doesn't provide
 // mutual exclusion for writes.
 func (ctx *passContext) reportWriteUnderSharedLock(obs observation, key fieldKey, guard guardInfo) {
-	if ctx.isSuppressed(obs.Func, obs.Pos) {
+	if ctx.isSuppressed(obs.Func, obs.Pos, "C6") {
 		return
 	}
 	structName := key.StructType.Obj().Name()
@@ -113,68 +202,98 @@ func (ctx *passContext) reportWriteUnderSharedLock(obs observation, key fieldKey
 	fieldName := st.Field(key.FieldIndex).Name()
 	mutexName := st.Field(guard.MutexFieldIndex).Name()
 
-	msg := fmt.Sprintf("field %s.%s is written while %s.%s is read-locked \u2014 use Lock() for write access",
-		structName, fieldName, structName, mutexName)
-
-	ctx.pass.Reportf(obs.Pos, "%s", msg)
+	ctx.report(obs.Pos, Finding{
+		Check: "C6",
+		Message: fmt.Sprintf("field %s.%s is written while %s.%s is read-locked \u2014 use Lock() for write access",
+			structName, fieldName, structName, mutexName),
+		Func:   ctx.funcName(obs.Func),
+		Type:   qualifiedTypeName(key.StructType),
+		Field:  fieldName,
+		Mutex:  structName + "." + mutexName,
+		Mode:   modeShared,
+		Access: accessWrite,
+	})
 }
 
 // reportDoubleLock emits a diagnostic for acquiring a lock that is already held.
 func (ctx *passContext) reportDoubleLock(fn *ssa.Function, pos token.Pos, ref *lockRef) {
-	if ctx.isSuppressed(fn, pos) {
+	if ctx.isSuppressed(fn, pos, "C2") {
 		return
 	}
 	name := lockRefName(*ref)
 	if name == "" {
 		return
 	}
-	ctx.pass.Reportf(pos, "%s is already held when locking %s", name, name)
+	ctx.report(pos, withLockRef(Finding{
+		Check:   "C2",
+		Message: fmt.Sprintf("%s is already held when locking %s", name, name),
+		Func:    ctx.funcName(fn),
+	}, *ref))
 }
 
 // reportRecursiveRLock emits a diagnostic for recursive RLock — can deadlock
 // if a writer is waiting.
 func (ctx *passContext) reportRecursiveRLock(fn *ssa.Function, pos token.Pos, ref *lockRef) {
-	if ctx.isSuppressed(fn, pos) {
+	if ctx.isSuppressed(fn, pos, "C6") {
 		return
 	}
 	name := lockRefName(*ref)
 	if name == "" {
 		return
 	}
-	ctx.pass.Reportf(pos, "recursive RLock on %s \u2014 can deadlock if a writer is waiting", name)
+	ctx.report(pos, withLockRef(Finding{
+		Check:   "C6",
+		Message: fmt.Sprintf("recursive RLock on %s \u2014 can deadlock if a writer is waiting", name),
+		Func:    ctx.funcName(fn),
+		Mode:    modeShared,
+	}, *ref))
 }
 
 // reportLockUpgradeAttempt emits a diagnostic for Lock() while RLock is held — deadlock.
 func (ctx *passContext) reportLockUpgradeAttempt(fn *ssa.Function, pos token.Pos, ref *lockRef) {
-	if ctx.isSuppressed(fn, pos) {
+	if ctx.isSuppressed(fn, pos, "C6") {
 		return
 	}
 	name := lockRefName(*ref)
 	if name == "" {
 		return
 	}
-	ctx.pass.Reportf(pos, "%s.Lock() called while %s is read-locked \u2014 lock upgrade can deadlock", name, name)
+	ctx.report(pos, withLockRef(Finding{
+		Check:   "C6",
+		Message: fmt.Sprintf("%s.Lock() called while %s is read-locked \u2014 lock upgrade can deadlock", name, name),
+		Func:    ctx.funcName(fn),
+		Mode:    modeShared,
+	}, *ref))
 }
 
 // reportMismatchedUnlock emits a diagnostic for calling the wrong unlock method.
 func (ctx *passContext) reportMismatchedUnlock(fn *ssa.Function, pos token.Pos, ref *lockRef, wasExclusive bool, unlockMethod string) {
-	if ctx.isSuppressed(fn, pos) {
+	if ctx.isSuppressed(fn, pos, "C6") {
 		return
 	}
 	name := lockRefName(*ref)
 	if name == "" {
 		return
 	}
+	want := "RUnlock"
 	if wasExclusive {
-		ctx.pass.Reportf(pos, "%s is exclusively locked but %s() was called \u2014 use Unlock()", name, unlockMethod)
-	} else {
-		ctx.pass.Reportf(pos, "%s is read-locked but %s() was called \u2014 use RUnlock()", name, unlockMethod)
+		want = "Unlock"
 	}
+	msg := fmt.Sprintf("%s is read-locked but %s() was called \u2014 use RUnlock()", name, unlockMethod)
+	if wasExclusive {
+		msg = fmt.Sprintf("%s is exclusively locked but %s() was called \u2014 use Unlock()", name, unlockMethod)
+	}
+	ctx.reportWithFixes(pos, withLockRef(Finding{
+		Check:   "C6",
+		Message: msg,
+		Func:    ctx.funcName(fn),
+		Mode:    lockMode(wasExclusive),
+	}, *ref), ctx.renameMethodFix(pos, unlockMethod, want))
 }
 
 // reportDeferredLockInsteadOfUnlock emits a diagnostic for `defer mu.Lock()` typo.
 func (ctx *passContext) reportDeferredLockInsteadOfUnlock(fn *ssa.Function, pos token.Pos, ref *lockRef, methodName string) {
-	if ctx.isSuppressed(fn, pos) {
+	if ctx.isSuppressed(fn, pos, "C7") {
 		return
 	}
 	name := lockRefName(*ref)
@@ -185,7 +304,12 @@ func (ctx *passContext) reportDeferredLockInsteadOfUnlock(fn *ssa.Function, pos
 	if methodName == "RLock" {
 		suggestion = "RUnlock"
 	}
-	ctx.pass.Reportf(pos, "defer %s.%s() will deadlock \u2014 did you mean defer %s.%s()?", name, methodName, name, suggestion)
+	ctx.reportWithFixes(pos, withLockRef(Finding{
+		Check:   "C7",
+		Message: fmt.Sprintf("defer %s.%s() will deadlock \u2014 did you mean defer %s.%s()?", name, methodName, name, suggestion),
+		Func:    ctx.funcName(fn),
+		Mode:    lockMode(methodName == "Lock"),
+	}, *ref), ctx.renameMethodFix(pos, methodName, suggestion))
 }
 
 // computeReturnsHolding derives per-function ReturnsHolding postconditions from
@@ -201,6 +325,10 @@ func (ctx *passContext) computeReturnsHolding() {
 
 	for _, candidates := range ctx.lockLeakCandidates {
 		for _, c := range candidates {
+			// A lock handed off to a spawned goroutine (C12) is not a postcondition.
+			if ctx.lockHandoffs[lockHandoffKey{fn: c.Fn, ref: c.Ref}] {
+				continue
+			}
 			mfk, ok := lockRefToMutexFieldKey(&c.Ref)
 			if !ok {
 				continue
@@ -226,7 +354,11 @@ func (ctx *passContext) computeReturnsHolding() {
 	}
 
 	// A function has ReturnsHolding(mfk) if every return is a candidate.
+	// A contract replaces inference.
 	for key, positions := range returnPositions {
+		if ctx.contract(key.fn) != nil {
+			continue
+		}
 		if len(positions) == returnCount[key.fn] && returnCount[key.fn] > 0 {
 			ctx.getOrCreateFuncFacts(key.fn).ReturnsHolding[key.mfk] = true
 		}
@@ -236,8 +368,12 @@ func (ctx *passContext) computeReturnsHolding() {
 // checkCallersOfAcquireHelpers checks callers of acquire helpers (functions with
 // ReturnsHolding) and reports when a caller never releases the acquired lock.
 func (ctx *passContext) checkCallersOfAcquireHelpers() {
-	// First, report callee-side diagnostics for acquire helpers.
+	// First, report callee-side diagnostics for acquire helpers. Helpers
+	// declaring //mu:acquires are intentional.
 	for fn, facts := range ctx.funcFacts {
+		if ctx.contract(fn) != nil {
+			continue
+		}
 		for mfk := range facts.ReturnsHolding {
 			ctx.reportAcquireHelper(fn, mfk)
 		}
@@ -272,28 +408,36 @@ func (ctx *passContext) checkCallersOfAcquireHelpers() {
 // reportAcquireHelper emits a callee-side C13 diagnostic for a function that
 // returns while holding a lock on all paths.
 func (ctx *passContext) reportAcquireHelper(fn *ssa.Function, mfk mutexFieldKey) {
-	if ctx.isSuppressed(fn, fn.Pos()) {
+	if ctx.isSuppressed(fn, fn.Pos(), "C13") {
 		return
 	}
 	name := mutexFieldKeyName(mfk)
 	if name == "" {
 		return
 	}
-	ctx.pass.Reportf(fn.Pos(), "%s() returns while holding %s -- callers must unlock", fn.Name(), name)
+	ctx.report(fn.Pos(), withMutex(Finding{
+		Check:   "C13",
+		Message: fmt.Sprintf("%s() returns while holding %s -- callers must unlock", fn.Name(), name),
+		Func:    ctx.funcName(fn),
+	}, mfk))
 }
 
 // reportCallerMissingUnlock emits a caller-side C13 diagnostic for a caller
 // that never releases the lock acquired by an acquire helper.
 func (ctx *passContext) reportCallerMissingUnlock(cs callSiteRecord, mfk mutexFieldKey) {
-	if ctx.isSuppressed(cs.Caller, cs.Pos) {
+	if ctx.isSuppressed(cs.Caller, cs.Pos, "C13") {
 		return
 	}
 	name := mutexFieldKeyName(mfk)
 	if name == "" {
 		return
 	}
-	ctx.pass.Reportf(cs.Pos, "%s() calls %s() which acquires %s, but %s() never releases it",
-		cs.Caller.Name(), cs.Callee.Name(), name, cs.Caller.Name())
+	ctx.report(cs.Pos, withMutex(Finding{
+		Check: "C13",
+		Message: fmt.Sprintf("%s() calls %s() which acquires %s, but %s() never releases it",
+			cs.Caller.Name(), cs.Callee.Name(), name, cs.Caller.Name()),
+		Func: ctx.funcName(cs.Caller),
+	}, mfk), relatedAt(cs.Callee.Pos(), "%s() returns while holding %s", cs.Callee.Name(), name))
 }
 
 // reportDeferredLockLeaks iterates C5 candidates collected during Phase 1
@@ -308,6 +452,10 @@ func (ctx *passContext) reportDeferredLockLeaks() {
 			if ctx.deferredLockTypoReported[deferredLockTypoKey{fn: c.Fn, ref: c.Ref}] {
 				continue
 			}
+			// Suppress C5 when the lock is handed off to a spawned goroutine (C12).
+			if ctx.lockHandoffs[lockHandoffKey{fn: c.Fn, ref: c.Ref}] {
+				continue
+			}
 			// Suppress C5 when C13 applies: the function is an acquire helper
 			// for this lock, so the leak is an intentional postcondition.
 			mfk, ok := lockRefToMutexFieldKey(&c.Ref)
@@ -321,7 +469,7 @@ func (ctx *passContext) reportDeferredLockLeaks() {
 
 // reportLockLeak emits a C5 diagnostic for returning without unlocking a held mutex.
 func (ctx *passContext) reportLockLeak(c lockLeakCandidate) {
-	if ctx.isSuppressed(c.Fn, c.Pos) {
+	if ctx.isSuppressed(c.Fn, c.Pos, "C5") {
 		return
 	}
 	name := lockRefName(c.Ref)
@@ -329,8 +477,12 @@ func (ctx *passContext) reportLockLeak(c lockLeakCandidate) {
 		return
 	}
 	acquirePos := ctx.pass.Fset.Position(c.AcquirePos)
-	ctx.pass.Reportf(c.Pos, "return without unlocking %s (locked at %s:%d:%d)",
-		name, filepath.Base(acquirePos.Filename), acquirePos.Line, acquirePos.Column)
+	ctx.reportWithFixes(c.Pos, withLockRef(Finding{
+		Check: "C5",
+		Message: fmt.Sprintf("return without unlocking %s (locked at %s:%d:%d)",
+			name, filepath.Base(acquirePos.Filename), acquirePos.Line, acquirePos.Column),
+		Func: ctx.funcName(c.Fn),
+	}, c.Ref), ctx.deferUnlockFix(c.Fn, c.AcquirePos, c.Ref), relatedAt(c.AcquirePos, "%s locked here", name))
 }
 
 // reportDeferredUnlockOfUnlocked iterates C4 candidates collected during Phase 1
@@ -340,6 +492,11 @@ func (ctx *passContext) reportDeferredUnlockOfUnlocked() {
 		if ctx.functionRequiresMutex(c.Fn, &c.Ref) {
 			continue
 		}
+		// Suppress C4 when C12 applies: the lock was acquired by the
+		// goroutine that spawned this one.
+		if ctx.handedOffUnlocks[handedOffUnlockKey{fn: c.Fn, pos: c.Pos}] {
+			continue
+		}
 		// Suppress C4 when unlocking a lock returned-holding by a callee.
 		// The unlock is expected: the callee acquired the lock and the
 		// caller is correctly releasing it.
@@ -385,14 +542,203 @@ func (ctx *passContext) functionRequiresMutex(fn *ssa.Function, ref *lockRef) bo
 
 // reportUnlockOfUnlocked emits a C4 diagnostic for Unlock() when the mutex is not held.
 func (ctx *passContext) reportUnlockOfUnlocked(fn *ssa.Function, pos token.Pos, ref *lockRef) {
-	if ctx.isSuppressed(fn, pos) {
+	if ctx.isSuppressed(fn, pos, "C4") {
 		return
 	}
 	name := lockRefName(*ref)
 	if name == "" {
 		return
 	}
-	ctx.pass.Reportf(pos, "Unlock() called but %s is not held", name)
+	ctx.report(pos, withLockRef(Finding{
+		Check:   "C4",
+		Message: fmt.Sprintf("Unlock() called but %s is not held", name),
+		Func:    ctx.funcName(fn),
+	}, *ref))
+}
+
+// reportDeferredGoroutineSpawns iterates C8 candidates collected during Phase 1
+// and reports each lock held across a go statement. When the spawned function
+// transitively acquires the same mutex, the diagnostic is escalated: the new
+// goroutine blocks until the spawner releases the lock, which deadlocks if the
+// spawner waits for it.
+func (ctx *passContext) reportDeferredGoroutineSpawns() {
+	for _, candidates := range ctx.goroutineSpawnCandidates {
+		for _, c := range candidates {
+			// The spawned goroutine releases this lock: reported as C12 instead.
+			if ctx.lockHandoffs[lockHandoffKey{fn: c.Fn, ref: c.Ref}] {
+				continue
+			}
+			reacquires := false
+			if mfk, ok := lockRefToMutexFieldKey(&c.Ref); ok && c.Target != nil {
+				if facts, ok := ctx.funcFacts[c.Target]; ok && facts.AcquiresTransitive[mfk] {
+					reacquires = true
+				}
+			}
+			ctx.reportGoroutineSpawnWhileLocked(c, reacquires)
+		}
+	}
+}
+
+// reportGoroutineSpawnWhileLocked emits a C8 diagnostic for a go statement
+// executed while a lock is held, an error when the goroutine reacquires it.
+func (ctx *passContext) reportGoroutineSpawnWhileLocked(c goroutineSpawnCandidate, reacquires bool) {
+	if ctx.isSuppressed(c.Fn, c.Pos, "C8") {
+		return
+	}
+	name := lockRefName(c.Ref)
+	if name == "" {
+		return
+	}
+	f := Finding{
+		Check:   "C8",
+		Message: fmt.Sprintf("goroutine spawned while holding %s", name),
+		Func:    ctx.funcName(c.Fn),
+	}
+	if reacquires {
+		f.Message = fmt.Sprintf("goroutine spawned while holding %s also locks %s \u2014 potential deadlock if the spawner waits for it", name, name)
+		f.Severity = SeverityError
+	}
+	ctx.report(c.Pos, withLockRef(f, c.Ref), relatedAt(c.AcquirePos, "%s locked here", name))
+}
+
+// reportDeferredBlockingOps iterates C9 candidates collected during Phase 1
+// and reports blocking operations performed while a lock is held, filtered by
+// concurrent context.
+func (ctx *passContext) reportDeferredBlockingOps() {
+	for _, candidates := range ctx.blockingOpCandidates {
+		for _, c := range candidates {
+			if !ctx.isConcurrent(c.Fn) {
+				continue
+			}
+			ctx.reportBlockingOpWhileLocked(c)
+		}
+	}
+}
+
+// reportBlockingOpWhileLocked emits a C9 diagnostic for a blocking operation
+// performed while a lock is held.
+func (ctx *passContext) reportBlockingOpWhileLocked(c blockingOpCandidate) {
+	if ctx.isSuppressed(c.Fn, c.Pos, "C9") {
+		return
+	}
+	name := lockRefName(c.Ref)
+	if name == "" {
+		return
+	}
+	ctx.report(c.Pos, withLockRef(Finding{
+		Check:   "C9",
+		Message: fmt.Sprintf("%s while holding %s \u2014 may block indefinitely", c.Desc, name),
+		Func:    ctx.funcName(c.Fn),
+	}, c.Ref))
+}
+
+// checkBlockingCallsUnderLock reports call sites where the caller holds a lock
+// and the callee may block (directly or transitively). Known blocking calls
+// are skipped: they are already reported as direct blocking operations.
+func (ctx *passContext) checkBlockingCallsUnderLock() {
+	for _, cs := range ctx.callSites {
+		if len(cs.HeldByStructType) == 0 && len(cs.HeldGlobals) == 0 {
+			continue
+		}
+		if !ctx.isConcurrent(cs.Caller) {
+			continue
+		}
+		calleeFacts, ok := ctx.funcFacts[cs.Callee]
+		if !ok || !calleeFacts.MayBlock {
+			continue
+		}
+		if _, known := blockingStaticCallDesc(cs.Callee); known {
+			continue
+		}
+		for _, mfk := range heldMutexKeys(cs) {
+			ctx.reportBlockingCallUnderLock(cs, mfk)
+		}
+	}
+}
+
+// reportBlockingCallUnderLock emits a C9 diagnostic for a call to a function
+// that may block while the caller holds a lock.
+func (ctx *passContext) reportBlockingCallUnderLock(cs callSiteRecord, mfk mutexFieldKey) {
+	if ctx.isSuppressed(cs.Caller, cs.Pos, "C9") {
+		return
+	}
+	name := mutexFieldKeyName(mfk)
+	if name == "" {
+		return
+	}
+	f := withMutex(Finding{
+		Check:   "C9",
+		Message: fmt.Sprintf("%s is held when calling %s() which may block", name, cs.Callee.Name()),
+		Func:    ctx.funcName(cs.Caller),
+	}, mfk)
+	if chain := ctx.blockingChain(cs.Callee); len(chain) > 0 {
+		f.Provenance = [][]ProvenanceStep{chain}
+	}
+	ctx.report(cs.Pos, f)
+}
+
+// blockingChain builds the provenance chain explaining why fn may block,
+// following MayBlockOrigin through callees down to the blocking operation.
+// Origins are only recorded in verbose mode.
+func (ctx *passContext) blockingChain(fn *ssa.Function) []ProvenanceStep {
+	const maxDepth = 5
+	var steps []ProvenanceStep
+	for depth := 0; depth < maxDepth; depth++ {
+		facts, ok := ctx.funcFacts[fn]
+		if !ok || facts.MayBlockOrigin == nil {
+			break
+		}
+		origin := facts.MayBlockOrigin
+		if origin.ViaCallee != nil {
+			steps = append(steps, ProvenanceStep{
+				Func:   fn.Name(),
+				Kind:   "calls",
+				Target: origin.ViaCallee.Name(),
+				Pos:    ctx.position(origin.ViaCallPos),
+			})
+			fn = origin.ViaCallee
+			continue
+		}
+		steps = append(steps, ProvenanceStep{
+			Func:   fn.Name(),
+			Kind:   "blocks",
+			Target: origin.Desc,
+			Pos:    ctx.position(origin.Pos),
+		})
+		break
+	}
+	return steps
+}
+
+// reportCrossGoroutineUnlocks emits C12 diagnostics collected in Phase 3.6.
+func (ctx *passContext) reportCrossGoroutineUnlocks() {
+	for _, u := range ctx.crossGoroutineUnlocks {
+		ctx.reportCrossGoroutineUnlock(u)
+	}
+}
+
+// reportCrossGoroutineUnlock emits a C12 diagnostic for a lock released in a
+// goroutine other than the one that acquired it.
+func (ctx *passContext) reportCrossGoroutineUnlock(u crossGoroutineUnlock) {
+	if ctx.isSuppressed(u.Fn, u.Pos, "C12") {
+		return
+	}
+	name := lockRefName(u.Ref)
+	if name == "" {
+		return
+	}
+	spawnPos := ctx.pass.Fset.Position(u.SpawnPos)
+	acquirePos := ctx.pass.Fset.Position(u.AcquirePos)
+	ctx.report(u.Pos, withLockRef(Finding{
+		Check: "C12",
+		Message: fmt.Sprintf("%s unlocked in goroutine spawned at %s:%d:%d \u2014 lock was acquired in parent goroutine at %s:%d:%d",
+			name,
+			filepath.Base(spawnPos.Filename), spawnPos.Line, spawnPos.Column,
+			filepath.Base(acquirePos.Filename), acquirePos.Line, acquirePos.Column),
+		Func: ctx.funcName(u.Fn),
+	}, u.Ref),
+		relatedAt(u.SpawnPos, "goroutine spawned here"),
+		relatedAt(u.AcquirePos, "%s locked here", name))
 }
 
 // detectAndReportLockOrderCycles runs cycle detection on the lock-order graph
@@ -423,7 +769,7 @@ func (ctx *passContext) reportLockOrderCycle(cycle lockOrderCycle) {
 
 	// Use the first edge's position for the diagnostic.
 	edge := cycle[0]
-	if ctx.isSuppressed(edge.Fn, edge.Pos) {
+	if ctx.isSuppressed(edge.Fn, edge.Pos, "C3") {
 		return
 	}
 
@@ -438,17 +784,27 @@ func (ctx *passContext) reportLockOrderCycle(cycle lockOrderCycle) {
 		}
 	}
 
-	if len(names) < 2 {
-		// Self-edge: same type, different instances.
-		if len(names) == 1 {
-			ctx.pass.Reportf(edge.Pos, "potential deadlock: lock ordering cycle on %s", names[0])
-			return
-		}
+	if len(names) == 0 {
 		return
 	}
+	// Self-edge: same type, different instances.
+	msg := fmt.Sprintf("potential deadlock: lock ordering cycle on %s", names[0])
+	if len(names) >= 2 {
+		msg = fmt.Sprintf("potential deadlock: lock ordering cycle between %s and %s",
+			names[0], names[1])
+	}
 
-	ctx.pass.Reportf(edge.Pos, "potential deadlock: lock ordering cycle between %s and %s",
-		names[0], names[1])
+	// Every edge of the cycle is a related position.
+	var related []analysis.RelatedInformation
+	for _, e := range cycle {
+		related = append(related, relatedAt(e.Pos, "%s acquired while holding %s in %s()",
+			mutexFieldKeyName(e.To), mutexFieldKeyName(e.From), e.Fn.Name()))
+	}
+	ctx.report(edge.Pos, withMutex(Finding{
+		Check:   "C3",
+		Message: msg,
+		Func:    ctx.funcName(edge.Fn),
+	}, edge.From), related...)
 }
 
 // checkExportedGuardedFields warns about exported fields that are guarded by
@@ -456,7 +812,7 @@ func (ctx *passContext) reportLockOrderCycle(cycle lockOrderCycle) {
 func (ctx *passContext) checkExportedGuardedFields() {
 	for key, guard := range ctx.guards {
 		// Only check types defined in this package.
-		if key.StructType.Obj().Pkg() != ctx.pass.Pkg {
+		if !ctx.isLocal(key.StructType.Obj().Pkg()) {
 			continue
 		}
 
@@ -488,10 +844,51 @@ func (ctx *passContext) reportExportedGuardedField(key fieldKey, guard guardInfo
 	fieldName := field.Name()
 	mutexName := st.Field(guard.MutexFieldIndex).Name()
 
-	msg := fmt.Sprintf("field %s.%s is guarded by %s.%s but is exported \u2014 external packages can bypass the lock",
-		structName, fieldName, structName, mutexName)
+	ctx.report(field.Pos(), Finding{
+		Check: "C14",
+		Message: fmt.Sprintf("field %s.%s is guarded by %s.%s but is exported \u2014 external packages can bypass the lock",
+			structName, fieldName, structName, mutexName),
+		Type:  qualifiedTypeName(key.StructType),
+		Field: fieldName,
+		Mutex: structName + "." + mutexName,
+	})
+}
+
+// reportMutexCopy emits a C10 diagnostic for a copy of a struct containing a
+// mutex. The message names the fields the original mutex guards, since the
+// copied lock no longer protects them.
+func (ctx *passContext) reportMutexCopy(fn *ssa.Function, pos token.Pos, mfk mutexFieldKey) {
+	if ctx.isSuppressed(fn, pos, "C10") {
+		return
+	}
+	name := mutexFieldKeyName(mfk)
+	if name == "" {
+		return
+	}
+	st, ok := mfk.StructType.Underlying().(*types.Struct)
+	if !ok {
+		return
+	}
+
+	var guarded []string
+	for i := 0; i < st.NumFields(); i++ {
+		guard, ok := ctx.guards[fieldKey{StructType: mfk.StructType, FieldIndex: i}]
+		if ok && guard.MutexFieldIndex == mfk.FieldIndex {
+			guarded = append(guarded, mfk.StructType.Obj().Name()+"."+st.Field(i).Name())
+		}
+	}
 
-	ctx.pass.Reportf(field.Pos(), "%s", msg)
+	msg := fmt.Sprintf("%s copied here; locking the copy does not protect %s",
+		name, strings.Join(guarded, ", "))
+	if len(guarded) == 0 {
+		msg = fmt.Sprintf("%s copied here; locking the copy does not protect the original %s",
+			name, mfk.StructType.Obj().Name())
+	}
+	ctx.report(pos, withMutex(Finding{
+		Check:   "C10",
+		Message: msg,
+		Func:    ctx.funcName(fn),
+	}, mfk))
 }
 
 // checkInterproceduralViolations iterates call sites and reports:
@@ -518,7 +915,7 @@ func (ctx *passContext) checkInterproceduralViolations() {
 				if ctx.shouldSuppressDirectViolation(cs.Caller, mfk) {
 					continue
 				}
-				if isPrePublicationConstructorCall(cs) {
+				if ctx.isPrePublicationConstructorCall(cs) {
 					continue
 				}
 				ctx.reportMissingLockAtCallSite(cs, mfk)
@@ -537,58 +934,60 @@ func (ctx *passContext) checkInterproceduralViolations() {
 // reportMissingLockAtCallSite emits a diagnostic for a call where the callee
 // requires a lock that the caller doesn't hold.
 func (ctx *passContext) reportMissingLockAtCallSite(cs callSiteRecord, mfk mutexFieldKey) {
-	if ctx.isSuppressed(cs.Caller, cs.Pos) {
+	if ctx.isSuppressed(cs.Caller, cs.Pos, "C1") {
 		return
 	}
 	name := mutexFieldKeyName(mfk)
 	if name == "" {
 		return
 	}
-	msg := fmt.Sprintf("%s must be held when calling %s()", name, cs.Callee.Name())
-	if ctx.verbose {
-		reasons := ctx.formatRequirementReasons(cs.Callee, mfk, 3)
-		for _, line := range reasons {
-			msg += "\n" + line
-		}
-	}
-	ctx.pass.Reportf(cs.Pos, "%s", msg)
+	ctx.report(cs.Pos, withMutex(Finding{
+		Check:      "C1",
+		Message:    fmt.Sprintf("%s must be held when calling %s()", name, cs.Callee.Name()),
+		Func:       ctx.funcName(cs.Caller),
+		Provenance: ctx.requirementChains(cs.Callee, mfk, 3),
+	}, mfk))
 }
 
-// formatRequirementReasons builds provenance lines explaining why fn requires mfk.
-// Returns tab-prefixed explanation lines, capped at maxChains provenance chains.
-func (ctx *passContext) formatRequirementReasons(fn *ssa.Function, mfk mutexFieldKey, maxChains int) []string {
+// requirementChains builds provenance chains explaining why fn requires mfk,
+// capped at maxChains. Origins are only recorded in verbose mode.
+func (ctx *passContext) requirementChains(fn *ssa.Function, mfk mutexFieldKey, maxChains int) [][]ProvenanceStep {
 	facts, ok := ctx.funcFacts[fn]
 	if !ok {
 		return nil
 	}
-	origins := facts.RequiresOrigin[mfk]
-	if len(origins) == 0 {
-		return nil
-	}
 
-	var lines []string
-	chains := 0
-	for _, origin := range origins {
-		if chains >= maxChains {
+	var chains [][]ProvenanceStep
+	for _, origin := range sortedOrigins(facts.RequiresOrigin[mfk]) {
+		if len(chains) >= maxChains {
 			break
 		}
-		chainLines := ctx.formatSingleOrigin(fn, mfk, origin, 0)
-		if len(chainLines) == 0 {
-			continue
+		if chain := ctx.originChain(fn, mfk, origin, 0); len(chain) > 0 {
+			chains = append(chains, chain)
 		}
-		// Separate chains with a blank line (except the first).
-		if chains > 0 {
-			lines = append(lines, "")
+	}
+	return chains
+}
+
+// sortedOrigins returns origins ordered by source position. Origins are
+// recorded while iterating maps, so their order is otherwise unspecified.
+func sortedOrigins(origins []requirementOrigin) []requirementOrigin {
+	sorted := append([]requirementOrigin(nil), origins...)
+	pos := func(o requirementOrigin) token.Pos {
+		if o.ViaCallee != nil {
+			return o.ViaCallPos
 		}
-		lines = append(lines, chainLines...)
-		chains++
+		return o.AccessPos
 	}
-	return lines
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return pos(sorted[i]) < pos(sorted[j])
+	})
+	return sorted
 }
 
-// formatSingleOrigin formats one origin into explanation lines, recursing for transitive origins.
-// depth is capped to prevent runaway chains.
-func (ctx *passContext) formatSingleOrigin(fn *ssa.Function, mfk mutexFieldKey, origin requirementOrigin, depth int) []string {
+// originChain builds the provenance steps for one origin, recursing for
+// transitive origins. depth is capped to prevent runaway chains.
+func (ctx *passContext) originChain(fn *ssa.Function, mfk mutexFieldKey, origin requirementOrigin, depth int) []ProvenanceStep {
 	const maxDepth = 5
 	if depth >= maxDepth {
 		return nil
@@ -596,39 +995,49 @@ func (ctx *passContext) formatSingleOrigin(fn *ssa.Function, mfk mutexFieldKey,
 
 	if origin.ViaCallee != nil {
 		// Transitive: fn calls callee at pos.
-		pos := ctx.pass.Fset.Position(origin.ViaCallPos)
-		line := fmt.Sprintf("\t%s() calls %s() at %s:%d:%d",
-			fn.Name(), origin.ViaCallee.Name(),
-			filepath.Base(pos.Filename), pos.Line, pos.Column)
-		lines := []string{line}
+		steps := []ProvenanceStep{{
+			Func:   fn.Name(),
+			Kind:   "calls",
+			Target: origin.ViaCallee.Name(),
+			Pos:    ctx.position(origin.ViaCallPos),
+		}}
 
 		// Recurse into the callee's first direct origin to show the root cause.
 		calleeFacts, ok := ctx.funcFacts[origin.ViaCallee]
 		if ok {
-			for _, inner := range calleeFacts.RequiresOrigin[mfk] {
-				innerLines := ctx.formatSingleOrigin(origin.ViaCallee, mfk, inner, depth+1)
-				if len(innerLines) > 0 {
-					lines = append(lines, innerLines...)
+			for _, inner := range sortedOrigins(calleeFacts.RequiresOrigin[mfk]) {
+				innerSteps := ctx.originChain(origin.ViaCallee, mfk, inner, depth+1)
+				if len(innerSteps) > 0 {
+					steps = append(steps, innerSteps...)
 					break // only show the first root cause per chain
 				}
 			}
 		}
-		return lines
+		return steps
+	}
+
+	if origin.AccessPos.IsValid() && origin.Global != nil {
+		// Direct: fn accesses a package-level variable at pos.
+		return []ProvenanceStep{{
+			Func:   fn.Name(),
+			Kind:   "accesses",
+			Target: ctx.globalVarName(fn, origin.Global),
+			Pos:    ctx.position(origin.AccessPos),
+		}}
 	}
 
 	if origin.AccessPos.IsValid() {
 		// Direct: fn accesses field at pos.
-		pos := ctx.pass.Fset.Position(origin.AccessPos)
 		st, ok := origin.FieldKey.StructType.Underlying().(*types.Struct)
 		if !ok || origin.FieldKey.FieldIndex >= st.NumFields() {
 			return nil
 		}
-		structName := origin.FieldKey.StructType.Obj().Name()
-		fieldName := st.Field(origin.FieldKey.FieldIndex).Name()
-		line := fmt.Sprintf("\t%s() accesses %s.%s at %s:%d:%d",
-			fn.Name(), structName, fieldName,
-			filepath.Base(pos.Filename), pos.Line, pos.Column)
-		return []string{line}
+		return []ProvenanceStep{{
+			Func:   fn.Name(),
+			Kind:   "accesses",
+			Target: origin.FieldKey.StructType.Obj().Name() + "." + st.Field(origin.FieldKey.FieldIndex).Name(),
+			Pos:    ctx.position(origin.AccessPos),
+		}}
 	}
 
 	return nil
@@ -637,19 +1046,27 @@ func (ctx *passContext) formatSingleOrigin(fn *ssa.Function, mfk mutexFieldKey,
 // reportDoubleLockAtCallSite emits a diagnostic for a call where the caller
 // holds a lock that the callee also acquires.
 func (ctx *passContext) reportDoubleLockAtCallSite(cs callSiteRecord, mfk mutexFieldKey) {
-	if ctx.isSuppressed(cs.Caller, cs.Pos) {
+	if ctx.isSuppressed(cs.Caller, cs.Pos, "C2") {
 		return
 	}
 	name := mutexFieldKeyName(mfk)
 	if name == "" {
 		return
 	}
-	ctx.pass.Reportf(cs.Pos, "%s is already held when calling %s() which locks %s",
-		name, cs.Callee.Name(), name)
+	ctx.report(cs.Pos, withMutex(Finding{
+		Check: "C2",
+		Message: fmt.Sprintf("%s is already held when calling %s() which locks %s",
+			name, cs.Callee.Name(), name),
+		Func: ctx.funcName(cs.Caller),
+	}, mfk))
 }
 
-// mutexFieldKeyName resolves a mutexFieldKey to "StructName.fieldName".
+// mutexFieldKeyName resolves a mutexFieldKey to "StructName.fieldName", or to
+// the variable name for a package-level mutex.
 func mutexFieldKeyName(mfk mutexFieldKey) string {
+	if mfk.Global != nil {
+		return mfk.Global.Name()
+	}
 	st, ok := mfk.StructType.Underlying().(*types.Struct)
 	if !ok || mfk.FieldIndex >= st.NumFields() {
 		return ""
@@ -668,7 +1085,7 @@ func (ctx *passContext) reportInconsistentLockState(fn *ssa.Function, block *ssa
 		return
 	}
 
-	if ctx.isSuppressed(fn, pos) {
+	if ctx.isSuppressed(fn, pos, "C11") {
 		return
 	}
 
@@ -685,13 +1102,21 @@ func (ctx *passContext) reportInconsistentLockState(fn *ssa.Function, block *ssa
 			if name == "" {
 				continue
 			}
-			ctx.pass.Reportf(pos, "inconsistent lock state: %s is held on one branch but not the other", name)
+			ctx.report(pos, withLockRef(Finding{
+				Check:   "C11",
+				Message: fmt.Sprintf("inconsistent lock state: %s is held on one branch but not the other", name),
+				Func:    ctx.funcName(fn),
+			}, ref))
 		}
 	}
 }
 
-// lockRefName resolves a lockRef to "StructName.fieldName" for diagnostics.
+// lockRefName resolves a lockRef to "StructName.fieldName" for diagnostics, or
+// to the variable name for a package-level mutex.
 func lockRefName(ref lockRef) string {
+	if v, ok := globalMutexVar(ref); ok {
+		return v.Name()
+	}
 	if ref.kind != fieldLock {
 		return ""
 	}
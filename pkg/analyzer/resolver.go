@@ -10,10 +10,19 @@ import (
 // resolveLockRef traces an SSA value back to its origin and returns a lockRef
 // identifying the specific lock instance. Returns nil if the value cannot be
 // resolved to a known lock.
-func resolveLockRef(v ssa.Value) *lockRef {
+func (ctx *passContext) resolveLockRef(v ssa.Value) *lockRef {
 	// Unwrap pointer indirections and copies.
 	v = unwrapSSAValue(v)
 
+	// Package-level mutex: var mu sync.Mutex.
+	if g, ok := v.(*ssa.Global); ok {
+		ptr, ok := g.Type().(*types.Pointer)
+		if !ok || !ctx.isMutexType(ptr.Elem()) {
+			return nil
+		}
+		return &lockRef{kind: globalLock, base: g, fieldIndex: -1}
+	}
+
 	fa, ok := v.(*ssa.FieldAddr)
 	if !ok {
 		return nil
@@ -31,7 +40,7 @@ func resolveLockRef(v ssa.Value) *lockRef {
 		return nil
 	}
 	field := structType.Field(fa.Field)
-	if !isMutexType(field.Type()) {
+	if !ctx.isMutexType(field.Type()) {
 		return nil
 	}
 	base := canonicalizeBase(fa.X)
@@ -108,27 +117,51 @@ func resolvePhiIfUniform(phi *ssa.Phi, visited map[*ssa.Phi]bool) ssa.Value {
 	return unique
 }
 
-// isMutexType returns true if the type is sync.Mutex or sync.RWMutex.
-func isMutexType(t types.Type) bool {
+// isMutexType returns true if the type is sync.Mutex, sync.RWMutex or a
+// mutex type listed in the configuration.
+func (ctx *passContext) isMutexType(t types.Type) bool {
 	named, ok := t.(*types.Named)
 	if !ok {
 		return false
 	}
 	obj := named.Obj()
-	if obj == nil || obj.Pkg() == nil || obj.Pkg().Path() != "sync" {
+	if obj == nil || obj.Pkg() == nil {
 		return false
 	}
+	if obj.Pkg().Path() != "sync" {
+		return ctx.isCustomMutexType(named)
+	}
 	return obj.Name() == "Mutex" || obj.Name() == "RWMutex"
 }
 
-// isRWMutexType returns true if the type is sync.RWMutex specifically.
-func isRWMutexType(t types.Type) bool {
+// mutexFieldIndices returns the indices of the direct mutex fields of a struct,
+// including embedded ones.
+func (ctx *passContext) mutexFieldIndices(st *types.Struct) []int {
+	var indices []int
+	for i := 0; i < st.NumFields(); i++ {
+		if ctx.isMutexType(st.Field(i).Type()) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// isRWMutexType returns true if the type is sync.RWMutex specifically, or a
+// configured mutex type with an RLock method.
+func (ctx *passContext) isRWMutexType(t types.Type) bool {
 	named, ok := t.(*types.Named)
 	if !ok {
 		return false
 	}
 	obj := named.Obj()
-	return obj != nil && obj.Pkg() != nil && obj.Pkg().Path() == "sync" && obj.Name() == "RWMutex"
+	if obj == nil || obj.Pkg() == nil {
+		return false
+	}
+	if ctx.isCustomMutexType(named) {
+		m, _, _ := types.LookupFieldOrMethod(named, true, obj.Pkg(), "RLock")
+		return m != nil
+	}
+	return obj.Pkg().Path() == "sync" && obj.Name() == "RWMutex"
 }
 
 // isRWLockMethod returns true if the method is RLock or RUnlock.
@@ -139,7 +172,7 @@ func isRWLockMethod(name string) bool {
 // resolveEmbeddedMutexRef handles the wrapper-call case where the receiver is
 // a pointer to a struct that embeds sync.Mutex or sync.RWMutex. SSA can
 // generate (*S).Lock(s) calls where the receiver is *S, not *sync.Mutex.
-func resolveEmbeddedMutexRef(recv ssa.Value, methodName string) *lockRef {
+func (ctx *passContext) resolveEmbeddedMutexRef(recv ssa.Value, methodName string) *lockRef {
 	recv = unwrapSSAValue(recv)
 	ptrType, ok := recv.Type().Underlying().(*types.Pointer)
 	if !ok {
@@ -151,11 +184,11 @@ func resolveEmbeddedMutexRef(recv ssa.Value, methodName string) *lockRef {
 	}
 	for i := 0; i < structType.NumFields(); i++ {
 		field := structType.Field(i)
-		if !field.Anonymous() || !isMutexType(field.Type()) {
+		if !field.Anonymous() || !ctx.isMutexType(field.Type()) {
 			continue
 		}
 		// RLock/RUnlock require sync.RWMutex specifically.
-		if isRWLockMethod(methodName) && !isRWMutexType(field.Type()) {
+		if isRWLockMethod(methodName) && !ctx.isRWMutexType(field.Type()) {
 			continue
 		}
 		return &lockRef{kind: fieldLock, base: canonicalizeBase(recv), fieldIndex: i}
@@ -0,0 +1,122 @@
+package analyzer
+
+import (
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// RPC frameworks call the methods of a service implementation from the
+// goroutines serving requests. Generated code names the pieces consistently,
+// which is what the detection relies on:
+//
+//   - gRPC: RegisterFooServer(s, srv FooServer) registers the implementation
+//     of the FooServer interface, and implementations embed
+//     UnimplementedFooServer for forward compatibility.
+//   - ConnectRPC: NewFooHandler(svc FooHandler, ...) wraps the implementation
+//     of the FooHandler interface in an http.Handler, and implementations may
+//     embed UnimplementedFooHandler.
+//
+// The methods of the implementation that belong to the service interface are
+// concurrent entrypoints. Methods promoted from an embedded Unimplemented
+// type are not: they only return an error.
+
+// serviceRegistrationTargets returns the service methods of the
+// implementations passed to a RegisterFooServer or NewFooHandler call.
+func (ctx *passContext) serviceRegistrationTargets(call *ssa.Call) []*ssa.Function {
+	callee := call.Common().StaticCallee()
+	if callee == nil || callee.Signature.Recv() != nil {
+		return nil
+	}
+	name := callee.Name()
+	if !strings.HasPrefix(name, "Register") && !strings.HasPrefix(name, "New") {
+		return nil
+	}
+
+	var targets []*ssa.Function
+	for _, arg := range call.Common().Args {
+		mi, ok := arg.(*ssa.MakeInterface)
+		if !ok {
+			continue
+		}
+		iface := serviceInterface(mi.Type())
+		if iface == nil {
+			continue
+		}
+		ifaceName := iface.Obj().Name()
+		if name != "Register"+ifaceName && name != "New"+ifaceName {
+			continue
+		}
+		targets = append(targets, ctx.implementingMethods(mi.X.Type(), iface)...)
+	}
+	return targets
+}
+
+// embeddedServiceTargets returns the service methods of the types of the
+// package embedding an UnimplementedFooServer or UnimplementedFooHandler
+// struct, for the FooServer or FooHandler interface declared next to it.
+func (ctx *passContext) embeddedServiceTargets() []*ssa.Function {
+	var targets []*ssa.Function
+	for _, pkg := range ctx.packages() {
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() {
+				continue
+			}
+			st, ok := tn.Type().Underlying().(*types.Struct)
+			if !ok {
+				continue
+			}
+			for i := 0; i < st.NumFields(); i++ {
+				field := st.Field(i)
+				if !field.Embedded() {
+					continue
+				}
+				iface := unimplementedServiceInterface(field.Type())
+				if iface == nil {
+					continue
+				}
+				targets = append(targets, ctx.implementingMethods(types.NewPointer(tn.Type()), iface)...)
+			}
+		}
+	}
+	return targets
+}
+
+// serviceInterface returns t if it is a named interface whose name ends in
+// Server or Handler, the interfaces of generated service code.
+func serviceInterface(t types.Type) *types.Named {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return nil
+	}
+	if _, ok := named.Underlying().(*types.Interface); !ok {
+		return nil
+	}
+	name := named.Obj().Name()
+	if !strings.HasSuffix(name, "Server") && !strings.HasSuffix(name, "Handler") {
+		return nil
+	}
+	return named
+}
+
+// unimplementedServiceInterface returns the service interface FooServer (or
+// FooHandler) of an embedded UnimplementedFooServer (or
+// UnimplementedFooHandler) type, looked up in the package declaring it.
+func unimplementedServiceInterface(t types.Type) *types.Named {
+	named := receiverNamed(t)
+	if named == nil || named.Obj().Pkg() == nil {
+		return nil
+	}
+	ifaceName, ok := strings.CutPrefix(named.Obj().Name(), "Unimplemented")
+	if !ok {
+		return nil
+	}
+	tn, ok := named.Obj().Pkg().Scope().Lookup(ifaceName).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	return serviceInterface(tn.Type())
+}
@@ -3,6 +3,7 @@ package analyzer
 import (
 	"go/token"
 	"go/types"
+	"sort"
 
 	"golang.org/x/tools/go/ssa"
 )
@@ -16,8 +17,26 @@ type walkContext struct {
 }
 
 // collectObservations iterates over all source functions and walks their CFGs.
+// Anonymous functions are walked after their enclosing functions, outermost
+// first, so that closures passed to lock wrappers can start with the
+// wrapper's locks held (see callbackEntryState).
 func (ctx *passContext) collectObservations() {
+	var closures []*ssa.Function
 	for _, fn := range ctx.srcFuncs {
+		if fn.Parent() != nil {
+			closures = append(closures, fn)
+			continue
+		}
+		// Lock methods of configured mutex types implement the lock.
+		if ctx.isCustomMutexMethod(fn) {
+			continue
+		}
+		ctx.walkFunction(fn)
+	}
+	sort.SliceStable(closures, func(i, j int) bool {
+		return closureDepth(closures[i]) < closureDepth(closures[j])
+	})
+	for _, fn := range closures {
 		ctx.walkFunction(fn)
 	}
 }
@@ -33,7 +52,8 @@ func (ctx *passContext) walkFunction(fn *ssa.Function) {
 		exitStates:  make(map[*ssa.BasicBlock]*lockState),
 		inconsistentLockReported: make(map[*ssa.BasicBlock]bool),
 	}
-	ls := newLockState()
+	ls := ctx.callbackEntryState(fn)
+	ctx.seedContractLocks(fn, ls)
 	ctx.walkBlock(wctx, fn.Blocks[0], nil, ls)
 }
 
@@ -99,12 +119,23 @@ func (ctx *passContext) processInstruction(fn *ssa.Function, instr ssa.Instructi
 		ctx.checkDeferredLockInsteadOfUnlock(fn, inst)
 		// Record deferred unlock for C5 lock-leak detection.
 		ctx.recordDeferredUnlock(inst, ls)
+	case *ssa.Go:
+		ctx.checkGoroutineSpawnWithHeldLocks(fn, inst, ls)
 	case *ssa.Return:
 		ctx.checkReturnWithHeldLocks(fn, inst, ls)
 	case *ssa.Store:
 		ctx.processStore(fn, inst, ls)
 	case *ssa.UnOp:
+		if inst.Op == token.ARROW {
+			ctx.recordBlockingOp(fn, inst.Pos(), "channel receive", ls)
+		}
 		ctx.processRead(fn, inst, ls)
+	case *ssa.Send:
+		ctx.recordBlockingOp(fn, inst.Pos(), "channel send", ls)
+	case *ssa.Select:
+		if inst.Blocking {
+			ctx.recordBlockingOp(fn, inst.Pos(), "blocking select", ls)
+		}
 	}
 }
 
@@ -119,7 +150,7 @@ func (ctx *passContext) processCall(fn *ssa.Function, call *ssa.Call, ls *lockSt
 		recv := common.Value
 		methodName := common.Method.Name()
 		if isLockMethod(methodName) {
-			ref := resolveLockRef(recv)
+			ref := ctx.resolveLockRef(recv)
 			if ref != nil {
 				if isLockAcquire(methodName) {
 					ctx.checkAndRecordLockAcquire(fn, call.Pos(), ref, isExclusiveLock(methodName), ls)
@@ -127,28 +158,39 @@ func (ctx *passContext) processCall(fn *ssa.Function, call *ssa.Call, ls *lockSt
 					ctx.checkAndRecordUnlock(fn, call.Pos(), ref, isExclusiveUnlock(methodName), ls)
 				}
 			}
+		} else if desc, ok := blockingInvokeDesc(common); ok {
+			ctx.recordBlockingOp(fn, call.Pos(), desc, ls)
 		}
 		return
 	}
 
 	callee := common.StaticCallee()
 	if callee == nil {
+		// Invoking a function-typed parameter: remember which locks are held
+		// (lock wrapper detection).
+		if param, ok := common.Value.(*ssa.Parameter); ok {
+			ctx.recordLockedCallback(fn, param, ls)
+		}
 		return
 	}
 
-	recv := common.Args
-	if len(recv) == 0 {
-		return
+	if desc, ok := blockingStaticCallDesc(callee); ok {
+		ctx.recordBlockingOp(fn, call.Pos(), desc, ls)
 	}
 
+	if isRead, ok := atomicAccessKind(callee); ok && len(common.Args) > 0 {
+		ctx.processAtomicAccess(fn, common.Args[0], isRead, call.Pos(), ls)
+	}
+
+	recv := common.Args
 	methodName := callee.Name()
-	if isLockMethod(methodName) {
+	if isLockMethod(methodName) && len(recv) > 0 {
 		recvVal := recv[0]
 		var ref *lockRef
-		if isMutexReceiver(recvVal) {
-			ref = resolveLockRef(recvVal)
+		if ctx.isMutexReceiver(recvVal) {
+			ref = ctx.resolveLockRef(recvVal)
 		} else {
-			ref = resolveEmbeddedMutexRef(recvVal, methodName)
+			ref = ctx.resolveEmbeddedMutexRef(recvVal, methodName)
 		}
 		if ref != nil {
 			if isLockAcquire(methodName) {
@@ -165,7 +207,9 @@ func (ctx *passContext) processCall(fn *ssa.Function, call *ssa.Call, ls *lockSt
 	if callee.Signature.Recv() != nil && len(common.Args) > 0 {
 		receiverVal = common.Args[0]
 	}
-	ctx.recordCallSite(fn, callee, call.Pos(), ls, receiverVal)
+	ctx.recordCallSite(fn, callee, call.Pos(), ls, receiverVal, common.Args)
+	ctx.releaseCalleeLocks(fn, callee, common.Args, ls)
+	ctx.acquireCalleeLocks(callee, common.Args, call.Pos(), ls)
 }
 
 // checkAndRecordLockAcquire checks for intra-function double-lock (including
@@ -244,7 +288,7 @@ func (ctx *passContext) checkAndRecordUnlock(fn *ssa.Function, pos token.Pos, re
 
 // resolveDeferredLockRef extracts the lockRef and method name from a deferred call.
 // Returns nil if the deferred call is not a lock/unlock method.
-func resolveDeferredLockRef(d *ssa.Defer) (*lockRef, string) {
+func (ctx *passContext) resolveDeferredLockRef(d *ssa.Defer) (*lockRef, string) {
 	common := d.Common()
 
 	var methodName string
@@ -273,10 +317,10 @@ func resolveDeferredLockRef(d *ssa.Defer) (*lockRef, string) {
 	}
 
 	var ref *lockRef
-	if isMutexReceiver(recv) {
-		ref = resolveLockRef(recv)
+	if ctx.isMutexReceiver(recv) {
+		ref = ctx.resolveLockRef(recv)
 	} else {
-		ref = resolveEmbeddedMutexRef(recv, methodName)
+		ref = ctx.resolveEmbeddedMutexRef(recv, methodName)
 	}
 	return ref, methodName
 }
@@ -284,7 +328,7 @@ func resolveDeferredLockRef(d *ssa.Defer) (*lockRef, string) {
 // checkDeferredUnlockMismatch checks a deferred call for mismatched unlock mode
 // without modifying lock state (preserving existing defer semantics).
 func (ctx *passContext) checkDeferredUnlockMismatch(fn *ssa.Function, d *ssa.Defer, ls *lockState) {
-	ref, methodName := resolveDeferredLockRef(d)
+	ref, methodName := ctx.resolveDeferredLockRef(d)
 	if ref == nil || isLockAcquire(methodName) {
 		return
 	}
@@ -305,7 +349,7 @@ func (ctx *passContext) checkDeferredUnlockMismatch(fn *ssa.Function, d *ssa.Def
 // checkDeferredLockInsteadOfUnlock detects the typo `defer mu.Lock()` instead of
 // `defer mu.Unlock()`. Fires when the deferred method is a lock acquire.
 func (ctx *passContext) checkDeferredLockInsteadOfUnlock(fn *ssa.Function, d *ssa.Defer) {
-	ref, methodName := resolveDeferredLockRef(d)
+	ref, methodName := ctx.resolveDeferredLockRef(d)
 	if ref == nil || !isLockAcquire(methodName) {
 		return
 	}
@@ -315,7 +359,7 @@ func (ctx *passContext) checkDeferredLockInsteadOfUnlock(fn *ssa.Function, d *ss
 
 // recordDeferredUnlock records a deferred unlock in the lock state for C5 leak detection.
 func (ctx *passContext) recordDeferredUnlock(d *ssa.Defer, ls *lockState) {
-	ref, methodName := resolveDeferredLockRef(d)
+	ref, methodName := ctx.resolveDeferredLockRef(d)
 	if ref == nil || isLockAcquire(methodName) {
 		return
 	}
@@ -336,10 +380,15 @@ func (ctx *passContext) checkReturnWithHeldLocks(fn *ssa.Function, ret *ssa.Retu
 	delete(ctx.lockLeakCandidates, retPos)
 
 	var candidates []lockLeakCandidate
+	contract := ctx.contract(fn)
 	for ref, hl := range ls.held {
 		if ls.deferredUnlocks[ref] {
 			continue
 		}
+		// Locks the function's contract holds on return are not leaked.
+		if contract.heldOnReturn(ref) {
+			continue
+		}
 		candidates = append(candidates, lockLeakCandidate{
 			Fn:         fn,
 			Pos:        retPos,
@@ -350,24 +399,59 @@ func (ctx *passContext) checkReturnWithHeldLocks(fn *ssa.Function, ret *ssa.Retu
 	if len(candidates) > 0 {
 		ctx.lockLeakCandidates[retPos] = candidates
 	}
+	ctx.checkContractAtReturn(fn, ret, ls)
+}
+
+// checkGoroutineSpawnWithHeldLocks records a C8 candidate when a goroutine is
+// spawned while locks are held. Reporting is deferred until AcquiresTransitive
+// is known, so that spawning a function which re-acquires a held lock can be
+// escalated. Uses a map keyed by the go position to clear stale candidates on
+// block re-walks.
+func (ctx *passContext) checkGoroutineSpawnWithHeldLocks(fn *ssa.Function, g *ssa.Go, ls *lockState) {
+	goPos := g.Pos()
+	delete(ctx.goroutineSpawnCandidates, goPos)
+	if len(ls.held) == 0 {
+		return
+	}
+
+	target := extractGoTarget(g)
+	var candidates []goroutineSpawnCandidate
+	for ref, hl := range ls.held {
+		candidates = append(candidates, goroutineSpawnCandidate{
+			Fn:         fn,
+			Pos:        goPos,
+			Ref:        ref,
+			AcquirePos: hl.pos,
+			Target:     target,
+		})
+	}
+	ctx.goroutineSpawnCandidates[goPos] = candidates
 }
 
 // recordCallSite records a static call with the normalized lock state at the call point.
-func (ctx *passContext) recordCallSite(caller, callee *ssa.Function, pos token.Pos, ls *lockState, receiver ssa.Value) {
+func (ctx *passContext) recordCallSite(caller, callee *ssa.Function, pos token.Pos, ls *lockState, receiver ssa.Value, args []ssa.Value) {
 	cs := callSiteRecord{
 		Caller:           caller,
 		Callee:           callee,
 		Pos:              pos,
 		HeldByStructType: normalizeLockState(ls),
+		HeldGlobals:      heldGlobalMutexes(ls),
 		ReceiverValue:    receiver,
+		Args:             args,
 	}
 	ctx.callSites = append(ctx.callSites, cs)
 }
 
 // lockRefToMutexFieldKey normalizes a lockRef to a type-scoped mutexFieldKey.
-// Returns false if the lockRef cannot be normalized (non-field lock or unresolvable type).
+// Returns false if the lockRef cannot be normalized (unresolvable type).
 func lockRefToMutexFieldKey(ref *lockRef) (mutexFieldKey, bool) {
-	if ref == nil || ref.kind != fieldLock {
+	if ref == nil {
+		return mutexFieldKey{}, false
+	}
+	if v, ok := globalMutexVar(*ref); ok {
+		return mutexFieldKey{Global: v}, true
+	}
+	if ref.kind != fieldLock {
 		return mutexFieldKey{}, false
 	}
 	named, _, ok := resolveStructFromBase(ref.base)
@@ -388,17 +472,21 @@ func (ctx *passContext) recordLockAcquisition(fn *ssa.Function, ref *lockRef) {
 }
 
 // isMutexReceiver returns true if the value is a pointer to sync.Mutex or sync.RWMutex.
-func isMutexReceiver(v ssa.Value) bool {
+func (ctx *passContext) isMutexReceiver(v ssa.Value) bool {
 	t := v.Type()
 	ptr, ok := t.(*types.Pointer)
 	if !ok {
 		return false
 	}
-	return isMutexType(ptr.Elem())
+	return ctx.isMutexType(ptr.Elem())
 }
 
 // processStore handles store instructions to record write observations.
 func (ctx *passContext) processStore(fn *ssa.Function, store *ssa.Store, ls *lockState) {
+	if _, ok := store.Addr.(*ssa.Global); ok {
+		ctx.processGlobalAccess(fn, store.Addr, false, store.Pos(), ls)
+		return
+	}
 	base, fieldIdx, structType, ok := resolveFieldAccess(store.Addr)
 	if !ok {
 		return
@@ -407,7 +495,7 @@ func (ctx *passContext) processStore(fn *ssa.Function, store *ssa.Store, ls *loc
 	if !stOk || fieldIdx >= st.NumFields() {
 		return
 	}
-	if isMutexType(st.Field(fieldIdx).Type()) {
+	if ctx.isMutexType(st.Field(fieldIdx).Type()) {
 		return
 	}
 
@@ -433,6 +521,12 @@ func (ctx *passContext) processStore(fn *ssa.Function, store *ssa.Store, ls *loc
 
 // processRead handles UnOp (dereference) instructions to record read observations.
 func (ctx *passContext) processRead(fn *ssa.Function, unop *ssa.UnOp, ls *lockState) {
+	if _, ok := unop.X.(*ssa.Global); ok {
+		if unop.Op == token.MUL {
+			ctx.processGlobalAccess(fn, unop.X, !updatesMap(unop), unop.Pos(), ls)
+		}
+		return
+	}
 	base, fieldIdx, structType, ok := resolveFieldAccess(unop.X)
 	if !ok {
 		return
@@ -441,7 +535,7 @@ func (ctx *passContext) processRead(fn *ssa.Function, unop *ssa.UnOp, ls *lockSt
 	if !stOk || fieldIdx >= st.NumFields() {
 		return
 	}
-	if isMutexType(st.Field(fieldIdx).Type()) {
+	if ctx.isMutexType(st.Field(fieldIdx).Type()) {
 		return
 	}
 
@@ -489,7 +583,7 @@ func (ctx *passContext) recordAncestorObservations(fn *ssa.Function, primaryFA *
 		if !stOk || fieldIdx >= st.NumFields() {
 			break
 		}
-		if isMutexType(st.Field(fieldIdx).Type()) {
+		if ctx.isMutexType(st.Field(fieldIdx).Type()) {
 			break
 		}
 
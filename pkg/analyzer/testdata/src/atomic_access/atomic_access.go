@@ -0,0 +1,128 @@
+package atomic_access
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// --- Field accessed only through atomic functions: no guard inferred ---
+
+type Stats struct {
+	mu       sync.Mutex
+	requests int64
+	names    []string
+}
+
+func (s *Stats) Record(name string) {
+	atomic.AddInt64(&s.requests, 1) // no diagnostic: atomic access
+	s.mu.Lock()
+	s.names = append(s.names, name)
+	s.mu.Unlock()
+}
+
+func (s *Stats) Requests() int64 {
+	return atomic.LoadInt64(&s.requests) // no diagnostic: atomic access
+}
+
+func (s *Stats) Reset() {
+	s.mu.Lock()
+	atomic.StoreInt64(&s.requests, 0)
+	s.mu.Unlock()
+}
+
+// --- Fields of sync/atomic types are excluded from inference ---
+
+type Gauge struct {
+	mu      sync.Mutex
+	current atomic.Int64
+	last    atomic.Pointer[string]
+	label   string
+}
+
+func (g *Gauge) Set(v int64, label string) {
+	g.mu.Lock()
+	g.current.Store(v)
+	g.last.Store(&label)
+	g.label = label
+	g.mu.Unlock()
+}
+
+func (g *Gauge) Get() int64 {
+	return g.current.Load() // no diagnostic: atomic.Int64 is self-synchronized
+}
+
+func (g *Gauge) Last() *string {
+	return g.last.Load() // no diagnostic: atomic.Pointer is self-synchronized
+}
+
+// --- Mixed atomic and plain access ---
+
+type Conn struct {
+	mu     sync.Mutex
+	closed int32
+	buf    []byte
+}
+
+func (c *Conn) Close() {
+	atomic.StoreInt32(&c.closed, 1)
+}
+
+func (c *Conn) IsClosed() bool {
+	return c.closed == 1 // want `field Conn\.closed is accessed atomically at atomic_access\.go:\d+:\d+ but plainly here`
+}
+
+func (c *Conn) Write(b []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed == 1 { // want `field Conn\.closed is accessed atomically at atomic_access\.go:\d+:\d+ but plainly here`
+		return
+	}
+	c.buf = append(c.buf, b...)
+}
+
+// --- Mixed access where the plain access holds the inferred guard ---
+
+type Pool struct {
+	mu   sync.Mutex
+	size int64
+}
+
+func (p *Pool) Grow() {
+	p.mu.Lock()
+	p.size++
+	p.mu.Unlock()
+}
+
+func (p *Pool) Shrink() {
+	p.mu.Lock()
+	p.size--
+	p.mu.Unlock()
+}
+
+func (p *Pool) Peek() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return atomic.LoadInt64(&p.size)
+}
+
+func (p *Pool) Unsafe() int64 {
+	return p.size // want `field Pool\.size is accessed atomically at atomic_access\.go:\d+:\d+ but plainly here`
+}
+
+// --- Mixed access is C16, suppressed on its own ---
+
+func (c *Conn) Debug() bool {
+	return c.closed == 1 //mu:nolint:C16 // approximate value for logging
+}
+
+func (c *Conn) DebugC1() bool {
+	return c.closed == 1 //mu:nolint:C1 // want `field Conn\.closed is accessed atomically at atomic_access\.go:\d+:\d+ but plainly here`
+}
+
+// --- Constructors may initialize atomic fields plainly ---
+
+func NewConn() *Conn {
+	c := &Conn{}
+	c.closed = 0 // no diagnostic: constructor
+	return c
+}
@@ -0,0 +1,36 @@
+package baseline
+
+import "sync"
+
+// TestBaseline records one C1 finding in (*Counter).Peek and the lock leak in
+// (*Counter).Reset. The second unguarded read in Peek and the one in Total are
+// new and still reported.
+
+type Counter struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (c *Counter) Inc() {
+	c.mu.Lock()
+	c.count++
+	c.mu.Unlock()
+}
+
+func (c *Counter) Peek() int {
+	n := c.count
+	return n + c.count // want `field Counter\.count is accessed without holding Counter\.mu`
+}
+
+func (c *Counter) Total() int {
+	return c.count // want `field Counter\.count is accessed without holding Counter\.mu`
+}
+
+func (c *Counter) Reset(cond bool) {
+	c.mu.Lock()
+	if cond {
+		return
+	}
+	c.count = 0
+	c.mu.Unlock()
+}
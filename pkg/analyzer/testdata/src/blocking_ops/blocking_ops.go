@@ -0,0 +1,187 @@
+package blocking_ops
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// --- Channel send while holding lock ---
+
+type Dispatcher struct {
+	mu     sync.Mutex
+	queue  []int
+	notify chan struct{}
+}
+
+func (d *Dispatcher) Enqueue(job int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.queue = append(d.queue, job)
+	d.notify <- struct{}{} // want `channel send while holding Dispatcher\.mu \x{2014} may block indefinitely`
+}
+
+// --- Channel receive while holding lock ---
+
+func (d *Dispatcher) WaitNotify() {
+	d.mu.Lock()
+	<-d.notify // want `channel receive while holding Dispatcher\.mu`
+	d.mu.Unlock()
+}
+
+// --- Blocking select while holding lock ---
+
+type Coordinator struct {
+	mu    sync.Mutex
+	state string
+	done  chan struct{}
+}
+
+func (c *Coordinator) WaitForDone() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select { // want `blocking select while holding Coordinator\.mu`
+	case <-c.done:
+		c.state = "done"
+	case <-time.After(5 * time.Second):
+		c.state = "timeout"
+	}
+}
+
+// Non-blocking select (has default) — no diagnostic.
+func (c *Coordinator) Poll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		c.state = "done"
+	default:
+	}
+}
+
+// --- Known blocking calls while holding lock ---
+
+type RateLimiter struct {
+	mu       sync.Mutex
+	tokens   int
+	interval time.Duration
+	wg       sync.WaitGroup
+	client   *http.Client
+}
+
+func (r *RateLimiter) Wait() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for r.tokens <= 0 {
+		time.Sleep(r.interval) // want `time\.Sleep\(\) while holding RateLimiter\.mu`
+	}
+	r.tokens--
+}
+
+func (r *RateLimiter) Drain() {
+	r.mu.Lock()
+	r.wg.Wait() // want `sync\.WaitGroup\.Wait\(\) while holding RateLimiter\.mu`
+	r.mu.Unlock()
+}
+
+func (r *RateLimiter) Fetch(req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	resp, err := r.client.Do(req) // want `http\.Client\.Do\(\) while holding RateLimiter\.mu`
+	if err == nil {
+		resp.Body.Close()
+	}
+}
+
+func (r *RateLimiter) ReadConn(conn net.Conn, buf []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	conn.Read(buf) // want `net\.Conn\.Read\(\) while holding RateLimiter\.mu`
+}
+
+func (r *RateLimiter) ReadAll(rd io.Reader, buf []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rd.Read(buf) // want `io\.Reader\.Read\(\) while holding RateLimiter\.mu`
+}
+
+// Source embeds io.Reader: its Read method is io.Reader's.
+type Source interface {
+	io.Reader
+	Name() string
+}
+
+func (r *RateLimiter) ReadSource(src Source, buf []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = src.Name() // no diagnostic: declared by Source, not known to block
+	src.Read(buf)  // want `io\.Reader\.Read\(\) while holding RateLimiter\.mu`
+}
+
+func (r *RateLimiter) ReadCloser(rc io.ReadCloser, buf []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rc.Read(buf) // want `io\.Reader\.Read\(\) while holding RateLimiter\.mu`
+}
+
+// --- Interprocedural: helper blocks, caller holds lock ---
+
+type Pipeline struct {
+	mu  sync.Mutex
+	out chan int
+	n   int
+}
+
+func (p *Pipeline) emit(v int) {
+	p.out <- v // no lock held here — no direct diagnostic
+}
+
+func (p *Pipeline) forward(v int) {
+	p.emit(v)
+}
+
+func (p *Pipeline) Push(v int) {
+	p.mu.Lock()
+	p.n++
+	p.forward(v) // want `Pipeline\.mu is held when calling forward\(\) which may block`
+	p.mu.Unlock()
+}
+
+// --- Correct pattern: release lock before blocking ---
+
+type SafeDispatcher struct {
+	mu     sync.Mutex
+	queue  []int
+	notify chan struct{}
+}
+
+func (d *SafeDispatcher) Enqueue(job int) {
+	d.mu.Lock()
+	d.queue = append(d.queue, job)
+	d.mu.Unlock()
+
+	d.notify <- struct{}{}
+}
+
+func (p *Pipeline) SafePush(v int) {
+	p.mu.Lock()
+	p.n++
+	p.mu.Unlock()
+	p.forward(v)
+}
+
+// --- Suppressed with //mu:nolint ---
+
+func (d *Dispatcher) EnqueueBuffered(job int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queue = append(d.queue, job)
+	//mu:nolint
+	d.notify <- struct{}{}
+}
@@ -0,0 +1,112 @@
+package callback_entrypoints
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"callback_entrypoints/workpool"
+)
+
+type Cache struct {
+	mu        sync.Mutex
+	expired   int
+	cancelled int
+	finalized int
+	cleaned   int
+	waited    int
+	grouped   int
+	tried     int
+	synced    int
+}
+
+func (c *Cache) Reset() {
+	c.mu.Lock()
+	c.expired = 0
+	c.cancelled = 0
+	c.finalized = 0
+	c.cleaned = 0
+	c.waited = 0
+	c.grouped = 0
+	c.tried = 0
+	c.synced = 0
+	c.mu.Unlock()
+}
+
+// --- time.AfterFunc ---
+
+func (c *Cache) expire() {
+	c.expired = 1 // want `field Cache\.expired is accessed without holding Cache\.mu`
+}
+
+func (c *Cache) scheduleExpiry() *time.Timer {
+	return time.AfterFunc(time.Minute, c.expire)
+}
+
+// --- context.AfterFunc ---
+
+func (c *Cache) watch(ctx context.Context) func() bool {
+	return context.AfterFunc(ctx, func() {
+		c.cancelled = 1 // want `field Cache\.cancelled is accessed without holding Cache\.mu`
+	})
+}
+
+// --- runtime.SetFinalizer and runtime.AddCleanup ---
+
+type handle struct {
+	c *Cache
+}
+
+func finalize(h *handle) {
+	h.c.finalized = 1 // want `field Cache\.finalized is accessed without holding Cache\.mu`
+}
+
+func (c *Cache) newHandle() *handle {
+	h := &handle{c: c}
+	runtime.SetFinalizer(h, finalize)
+	return h
+}
+
+func (c *Cache) trackHandle(h *handle) {
+	runtime.AddCleanup(h, func(c *Cache) {
+		c.cleaned = 1 // want `field Cache\.cleaned is accessed without holding Cache\.mu`
+	}, c)
+}
+
+// --- (*sync.WaitGroup).Go ---
+
+func (c *Cache) refresh() {
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		c.waited = 1 // want `field Cache\.waited is accessed without holding Cache\.mu`
+	})
+	wg.Wait()
+}
+
+// --- errgroup-shaped Go and TryGo methods ---
+
+func (c *Cache) reload(g *workpool.Group) error {
+	g.Go(func() error {
+		c.grouped = 1 // want `field Cache\.grouped is accessed without holding Cache\.mu`
+		return nil
+	})
+	g.TryGo(func() error {
+		c.tried = 1 // want `field Cache\.tried is accessed without holding Cache\.mu`
+		return nil
+	})
+	return g.Wait()
+}
+
+// --- Synchronous callbacks: no concurrent context ---
+
+func (c *Cache) sync(once *sync.Once) {
+	once.Do(func() {
+		c.synced = 1
+	})
+}
+
+// configure is not reachable from a callback.
+func (c *Cache) configure() {
+	c.expired = 0
+}
@@ -0,0 +1,10 @@
+// Package workpool mimics golang.org/x/sync/errgroup.
+package workpool
+
+type Group struct{}
+
+func (g *Group) Go(f func() error) {}
+
+func (g *Group) TryGo(f func() error) bool { return true }
+
+func (g *Group) Wait() error { return nil }
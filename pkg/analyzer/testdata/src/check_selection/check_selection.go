@@ -0,0 +1,32 @@
+package check_selection
+
+import "sync"
+
+// Run with -checks=-C5,-C14 -severity=C1=warning: the lock leak in Leak and
+// the exported guarded field Total are not reported.
+
+type Stats struct {
+	mu    sync.Mutex
+	count int
+	Total int
+}
+
+func (s *Stats) Inc() {
+	s.mu.Lock()
+	s.count++
+	s.Total++
+	s.mu.Unlock()
+}
+
+func (s *Stats) Peek() int {
+	return s.count // want `field Stats\.count is accessed without holding Stats\.mu`
+}
+
+func (s *Stats) Leak(cond bool) {
+	s.mu.Lock()
+	if cond {
+		return
+	}
+	s.count = 0
+	s.mu.Unlock()
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"sync"
+	"time"
+
+	"concurrent_context/pool"
+)
+
+type State struct { // want State:`FieldGuardFact\{1->0\}`
+	mu sync.Mutex
+	n  int
+}
+
+func (s *State) Set(n int) { // want Set:`FuncLockFact\{requires=\[\] acquires=\[State\.0\]\}`
+	s.mu.Lock()
+	s.n = n
+	s.mu.Unlock()
+}
+
+func (s *State) bump() {
+	s.n = 2 // want `field State\.n is accessed without holding State\.mu`
+}
+
+func setup(p *pool.Pool, s *State) {
+	p.Submit(func() {
+		s.n = 1 // want `field State\.n is accessed without holding State\.mu`
+	})
+	pool.Spawn(s.bump)
+	pool.After(time.Second, func() {
+		s.n = 3 // want `field State\.n is accessed without holding State\.mu`
+	})
+	pool.Later(func() {
+		s.n = 4 // want `field State\.n is accessed without holding State\.mu`
+	})
+	pool.Invoke(func() {
+		s.n = 5 // want `field State\.n is accessed without holding State\.mu`
+	})
+	pool.Run(func() {
+		s.n = 6 // runs on the caller's goroutine
+	})
+}
@@ -0,0 +1,51 @@
+// Package pool runs the callbacks of its callers on other goroutines.
+package pool
+
+import "time"
+
+type Pool struct {
+	tasks chan func()
+}
+
+// Submit launches task with a go statement.
+func (p *Pool) Submit(task func()) { // want Submit:`ConcurrentFact\{params=\[1\]\}`
+	go task()
+}
+
+// Spawn launches a closure calling f.
+func Spawn(f func()) { // want Spawn:`ConcurrentFact\{params=\[0\]\}`
+	go func() {
+		f()
+	}()
+}
+
+// After passes f to time.AfterFunc.
+func After(d time.Duration, f func()) { // want After:`ConcurrentFact\{params=\[1\]\}`
+	time.AfterFunc(d, f)
+}
+
+// Later passes f to Spawn, a function of this package.
+func Later(f func()) { // want Later:`ConcurrentFact\{params=\[0\]\}`
+	Spawn(f)
+}
+
+// Start runs the worker loop on its own goroutine.
+func (p *Pool) Start() {
+	go p.loop()
+}
+
+func (p *Pool) loop() {
+	for task := range p.tasks {
+		Invoke(task)
+	}
+}
+
+// Invoke calls f, also from the worker loop, a concurrent context.
+func Invoke(f func()) { // want Invoke:`ConcurrentFact\{params=\[0\]\}`
+	f()
+}
+
+// Run calls f on the caller's goroutine.
+func Run(f func()) {
+	f()
+}
@@ -0,0 +1,98 @@
+package config_file
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Settings come from golintmu.json in this directory.
+
+// --- constructorPrefixes: BuildRegistry initializes r without locking ---
+
+type Registry struct {
+	mu    sync.Mutex
+	items map[string]int
+	debug bool
+	Count int // exported and guarded: C14 is disabled
+}
+
+func BuildRegistry(r *Registry) {
+	r.items = make(map[string]int)
+}
+
+func (r *Registry) Put(k string, v int) {
+	r.mu.Lock()
+	r.items[k] = v
+	r.debug = true
+	r.Count++
+	r.mu.Unlock()
+}
+
+func (r *Registry) Debug() bool {
+	return r.debug // excluded field
+}
+
+// --- entrypoints: only Worker.Handle* runs concurrently ---
+
+type Worker struct {
+	mu   sync.Mutex
+	jobs int
+}
+
+func (w *Worker) Add() {
+	w.mu.Lock()
+	w.jobs++
+	w.mu.Unlock()
+}
+
+func (w *Worker) HandleJob() int {
+	return w.jobs // want `field Worker\.jobs is accessed without holding Worker\.mu`
+}
+
+func (w *Worker) Setup() {
+	w.jobs = 0 // not reachable from an entrypoint
+}
+
+// --- mutexTypes: SpinLock guards fields like sync.Mutex ---
+
+type SpinLock struct {
+	state atomic.Int32
+}
+
+func (l *SpinLock) Lock() {
+	for !l.state.CompareAndSwap(0, 1) {
+	}
+}
+
+func (l *SpinLock) Unlock() {
+	l.state.Store(0)
+}
+
+type Stats struct {
+	spin SpinLock
+	hits int
+}
+
+func (w *Worker) HandleHit(s *Stats) {
+	s.spin.Lock()
+	s.hits++
+	s.spin.Unlock()
+}
+
+func (w *Worker) HandlePeek(s *Stats) int {
+	return s.hits // want `field Stats\.hits is accessed without holding Stats\.spin`
+}
+
+// --- exclude.types: Metrics is never reported ---
+
+type Metrics struct {
+	mu    sync.Mutex
+	total int
+}
+
+func (w *Worker) HandleMetrics(m *Metrics) {
+	m.mu.Lock()
+	m.total++
+	m.mu.Unlock()
+	m.total = 0
+}
@@ -0,0 +1,20 @@
+package excluded
+
+import "sync"
+
+// The package is excluded by ../golintmu.json: nothing is reported.
+
+type Counter struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (c *Counter) Inc() {
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+}
+
+func (c *Counter) Get() int {
+	return c.n
+}
@@ -0,0 +1,27 @@
+package config_isolation
+
+import "config_file"
+
+// No golintmu.json applies here: config_file.SpinLock is a mutex type only in
+// the configuration of config_file, not in this package.
+
+type Meter struct {
+	spin config_file.SpinLock
+	n    int
+}
+
+func (m *Meter) Inc() {
+	m.spin.Lock()
+	m.n++
+	m.spin.Unlock()
+}
+
+func (m *Meter) Peek() int {
+	return m.n // no diagnostic: spin is not a mutex
+}
+
+func main() {
+	m := &Meter{}
+	go m.Inc()
+	go m.Peek()
+}
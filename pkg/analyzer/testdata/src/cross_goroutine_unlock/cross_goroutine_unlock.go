@@ -0,0 +1,83 @@
+package cross_goroutine_unlock
+
+import "sync"
+
+func process(b []byte) {}
+
+// --- Unlock delegated to a spawned closure ---
+
+type Pipeline struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (p *Pipeline) StartProcessing(data []byte) {
+	p.mu.Lock()
+	p.data = data
+
+	go func() {
+		process(p.data) // want `field Pipeline\.data is accessed without holding Pipeline\.mu`
+		p.mu.Unlock()   // want `Pipeline\.mu unlocked in goroutine spawned at cross_goroutine_unlock\.go:18:2 \x{2014} lock was acquired in parent goroutine at cross_goroutine_unlock\.go:15:11`
+	}()
+}
+
+// --- Unlock performed by a helper called from the spawned goroutine ---
+
+type Batch struct {
+	mu    sync.Mutex
+	items []int
+}
+
+func (b *Batch) release() {
+	b.mu.Unlock() // want `Batch\.mu unlocked in goroutine spawned at cross_goroutine_unlock\.go:\d+:\d+`
+}
+
+func (b *Batch) finish(n int) {
+	_ = n
+	b.release()
+}
+
+func (b *Batch) Flush() {
+	b.mu.Lock()
+	b.items = nil
+	go b.finish(len(b.items))
+}
+
+// --- Correct pattern: lock and unlock in the same goroutine ---
+
+type Worker struct {
+	mu    sync.Mutex
+	state int
+}
+
+func (w *Worker) Start() {
+	go func() {
+		w.mu.Lock()
+		w.state = 1
+		w.mu.Unlock()
+	}()
+}
+
+// --- Unrelated unlock in a goroutine is still C4 ---
+
+func (w *Worker) Stop() {
+	go func() {
+		w.mu.Unlock() // want `Unlock\(\) called but Worker\.mu is not held`
+	}()
+}
+
+// --- Suppressed with //mu:nolint ---
+
+type Gate struct {
+	mu   sync.Mutex
+	open bool
+}
+
+func (g *Gate) Open() {
+	g.mu.Lock()
+	g.open = true
+	go func() {
+		//mu:nolint
+		g.mu.Unlock()
+	}()
+}
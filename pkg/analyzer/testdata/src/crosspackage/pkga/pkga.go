@@ -26,3 +26,49 @@ func (s *Stats) LockedGet() int { // want LockedGet:`FuncLockFact\{requires=\[\]
 func (s *Stats) UnlockedHelper() int { // want UnlockedHelper:`FuncLockFact\{requires=\[Stats\.0\] acquires=\[\]\}`
 	return s.errorCount // want `field Stats\.errorCount is accessed without holding Stats\.mu`
 }
+
+// ConfigMu guards Config, a package-level map.
+var ConfigMu sync.Mutex
+
+var Config = map[string]string{} // want Config:`GlobalGuardFact\{ConfigMu\}`
+
+func SetConfig(k, v string) { // want SetConfig:`FuncLockFact\{requires=\[\] acquires=\[ConfigMu\]\}`
+	ConfigMu.Lock()
+	defer ConfigMu.Unlock()
+	Config[k] = v
+}
+
+func ResetConfig() { // want ResetConfig:`FuncLockFact\{requires=\[\] acquires=\[ConfigMu\]\}`
+	ConfigMu.Lock()
+	Config = map[string]string{}
+	ConfigMu.Unlock()
+}
+
+// WithLock runs fn while holding s.mu. Exported as a lock wrapper.
+func (s *Stats) WithLock(fn func()) { // want WithLock:`FuncLockFact\{requires=\[\] acquires=\[Stats\.0\] callback1=\[Stats\.0\]\}`
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn()
+}
+
+// ErrorsLocked returns the error count. Declared, not inferred: the contract
+// is exported in FuncLockFact.
+//
+//mu:requires s.mu
+func (s *Stats) ErrorsLocked() int { // want ErrorsLocked:`FuncLockFact\{requires=\[Stats\.0\] acquires=\[\]\}`
+	return s.errorCount
+}
+
+// ReleaseConfig unlocks ConfigMu, locked by the caller.
+//
+//mu:releases ConfigMu
+func ReleaseConfig() { // want ReleaseConfig:`FuncLockFact\{requires=\[ConfigMu\] acquires=\[\] releases=\[ConfigMu\]\}`
+	ConfigMu.Unlock()
+}
+
+// Counter is never accessed in this package: the guard of value comes from
+// its annotation alone.
+type Counter struct { // want Counter:`FieldGuardFact\{1->0\}`
+	mu    sync.Mutex
+	value int //mu:guardedby mu
+}
@@ -19,3 +19,39 @@ func callWithoutLock(s *pkga.Stats) int {
 func callLockedInternally(s *pkga.Stats) {
 	s.Inc()
 }
+
+// readConfig reads an imported guarded global without holding its mutex.
+// Tests GlobalGuardFact import.
+func readConfig(k string) string {
+	return pkga.Config[k] // want `global variable pkga\.Config is accessed without holding pkga\.ConfigMu`
+}
+
+// readConfigLocked holds the imported global mutex. No violation expected.
+func readConfigLocked(k string) string {
+	pkga.ConfigMu.Lock()
+	defer pkga.ConfigMu.Unlock()
+	return pkga.Config[k]
+}
+
+// incrementViaWrapper accesses an imported guarded field inside a callback
+// passed to an imported lock wrapper. Tests FuncLockFact callback import.
+func incrementViaWrapper(s *pkga.Stats) {
+	s.WithLock(func() {
+		s.RequestCount++
+	})
+}
+
+// callContractWithoutLock calls an imported function whose //mu:requires
+// contract is not satisfied.
+func callContractWithoutLock(s *pkga.Stats) int {
+	return s.ErrorsLocked() // want `Stats\.mu must be held when calling ErrorsLocked\(\)`
+}
+
+// lockAndRelease locks an imported global mutex and lets an imported
+// //mu:releases function unlock it. No lock leak expected.
+func lockAndRelease(k string) string {
+	pkga.ConfigMu.Lock()
+	v := pkga.Config[k]
+	pkga.ReleaseConfig()
+	return v
+}
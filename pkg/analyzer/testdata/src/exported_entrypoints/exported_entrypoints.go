@@ -0,0 +1,59 @@
+// Package exported_entrypoints is a library: no main, no go statements. The
+// test runs with -exported-entrypoints.
+package exported_entrypoints
+
+import "sync"
+
+type Cache struct {
+	mu    sync.Mutex
+	items map[string]string
+	hits  int
+}
+
+func NewCache() *Cache {
+	return &Cache{items: make(map[string]string)}
+}
+
+func (c *Cache) Set(k, v string) {
+	c.mu.Lock()
+	c.items[k] = v
+	c.hits = 0
+	c.mu.Unlock()
+}
+
+// Get is an exported method of a type with a mutex: an entrypoint.
+func (c *Cache) Get(k string) string {
+	c.hits++           // want `field Cache\.hits is accessed without holding Cache\.mu` `field Cache\.hits is accessed without holding Cache\.mu`
+	return c.lookup(k) // want `Cache\.mu must be held when calling lookup\(\)`
+}
+
+// lookup is reachable from Get.
+func (c *Cache) lookup(k string) string {
+	return c.items[k]
+}
+
+// Clear is an exported method of a type with a mutex: an entrypoint.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[string]string)
+}
+
+// dump is not reachable from an exported method.
+func (c *Cache) dump() map[string]string {
+	return c.items
+}
+
+// Options has no mutex: its exported methods are not entrypoints.
+type Options struct {
+	cache *Cache
+}
+
+func (o *Options) Warm(v string) {
+	o.cache.hits = len(v)
+}
+
+// Reset is a function, not a method: not an entrypoint.
+func Reset(c *Cache) {
+	c.hits = 0
+}
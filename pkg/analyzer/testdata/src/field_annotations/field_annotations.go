@@ -0,0 +1,111 @@
+package field_annotations
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// --- //mu:guardedby overrides the majority lock (tandem locks) ---
+
+// Conn writes state under both mu and ioMu, but only mu guards it.
+type Conn struct {
+	mu    sync.Mutex
+	ioMu  sync.Mutex
+	state int //mu:guardedby mu
+}
+
+func (c *Conn) Flush() {
+	c.ioMu.Lock()
+	c.mu.Lock()
+	c.state = 1
+	c.mu.Unlock()
+	c.ioMu.Unlock()
+}
+
+func (c *Conn) Write() {
+	c.ioMu.Lock()
+	c.state = 2 // want `field Conn\.state is accessed without holding Conn\.mu`
+	c.ioMu.Unlock()
+}
+
+func (c *Conn) Close() {
+	c.ioMu.Lock()
+	c.state = 3 // want `field Conn\.state is accessed without holding Conn\.mu`
+	c.ioMu.Unlock()
+}
+
+// --- //mu:guardedby on a field never accessed under a lock ---
+
+type Queue struct {
+	mu sync.Mutex
+	// items is only written by Push.
+	//
+	//mu:guardedby mu // Pop is coming
+	items []int
+}
+
+func (q *Queue) Push(v int) {
+	q.items = append(q.items, v) // want `field Queue\.items is accessed without holding Queue\.mu` `field Queue\.items is accessed without holding Queue\.mu`
+}
+
+// --- //mu:unguarded: intentionally racy fields ---
+
+type Stats struct {
+	mu       sync.Mutex
+	total    int
+	requests int   //mu:unguarded // approximate, read by the debug page
+	hits     int64 //mu:unguarded
+}
+
+func (s *Stats) Add(n int) {
+	s.mu.Lock()
+	s.total += n
+	s.requests++
+	s.mu.Unlock()
+}
+
+func (s *Stats) Requests() int {
+	return s.requests
+}
+
+func (s *Stats) Hit() {
+	atomic.AddInt64(&s.hits, 1)
+}
+
+func (s *Stats) ResetHits() {
+	s.hits = 0
+}
+
+// --- Invalid annotations ---
+
+type Bad struct {
+	mu    sync.Mutex
+	count int
+	a     int //mu:guardedby nope // want `//mu:guardedby directive: Bad has no field nope`
+	b     int //mu:guardedby count // want `//mu:guardedby directive: Bad\.count is not a mutex`
+	c     int /* want `//mu:guardedby directive names no mutex` */ //mu:guardedby
+}
+
+//mu:unguarded // want `//mu:unguarded directive must annotate a struct field`
+var global int
+
+func main() {
+	c := &Conn{}
+	q := &Queue{}
+	s := &Stats{}
+	b := &Bad{}
+	go c.Flush()
+	go c.Write()
+	go c.Close()
+	go q.Push(1)
+	go s.Add(1)
+	go s.Requests()
+	go s.Hit()
+	go s.ResetHits()
+	go func() {
+		b.mu.Lock()
+		b.a, b.b, b.c = 1, 2, 3
+		b.mu.Unlock()
+	}()
+	_ = global
+}
@@ -0,0 +1,27 @@
+// Package events mimics in-house frameworks running callbacks concurrently.
+package events
+
+import "time"
+
+type Bus struct{}
+
+func (b *Bus) Subscribe(topic string, fn func(payload string)) {}
+
+func Every(d time.Duration, fn func()) {}
+
+// Defer runs fn on the caller's goroutine: not configured.
+func Defer(fn func()) {}
+
+type Handler interface {
+	Handle(msg string) error
+}
+
+type Queue interface {
+	Consume(h Handler)
+}
+
+// Consumer is implemented by message-queue consumers, started by the
+// framework.
+type Consumer interface {
+	Consume(msg string)
+}
@@ -0,0 +1,80 @@
+package framework_entrypoints
+
+import (
+	"sync"
+	"time"
+
+	"framework_entrypoints/events"
+)
+
+// Settings come from golintmu.json in this directory.
+
+type State struct {
+	mu       sync.Mutex
+	events   int
+	ticks    int
+	messages int
+	consumed int
+	deferred int
+}
+
+func (s *State) Reset() {
+	s.mu.Lock()
+	s.events = 0
+	s.ticks = 0
+	s.messages = 0
+	s.consumed = 0
+	s.deferred = 0
+	s.mu.Unlock()
+}
+
+// --- entrypointArgs: method value passed to (*events.Bus).Subscribe ---
+
+func (s *State) onEvent(payload string) {
+	s.events++ // want `field State\.events is accessed without holding State\.mu` `field State\.events is accessed without holding State\.mu`
+}
+
+func (s *State) subscribe(b *events.Bus) {
+	b.Subscribe("orders", s.onEvent)
+}
+
+// --- entrypointArgs: closure passed to events.Every ---
+
+func (s *State) schedule() {
+	events.Every(time.Minute, func() {
+		s.ticks = 1 // want `field State\.ticks is accessed without holding State\.mu`
+	})
+}
+
+// --- entrypointArgs: interface method call, handler converted to events.Handler ---
+
+type messageHandler struct {
+	s *State
+}
+
+func (h messageHandler) Handle(msg string) error {
+	h.s.messages = 1 // want `field State\.messages is accessed without holding State\.mu`
+	return nil
+}
+
+func (s *State) consume(q events.Queue) {
+	q.Consume(messageHandler{s: s})
+}
+
+// --- entrypointInterfaces: types implementing events.Consumer ---
+
+type auditConsumer struct {
+	s *State
+}
+
+func (c *auditConsumer) Consume(msg string) {
+	c.s.consumed = 1 // want `field State\.consumed is accessed without holding State\.mu`
+}
+
+// --- Not configured: no concurrent context ---
+
+func (s *State) cleanup() {
+	events.Defer(func() {
+		s.deferred = 1
+	})
+}
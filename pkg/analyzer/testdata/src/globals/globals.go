@@ -0,0 +1,146 @@
+package globals
+
+import "sync"
+
+// --- Package-level mutex guarding a package-level map ---
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]int{} // no diagnostic: package initializer
+)
+
+func init() {
+	registry["default"] = 0 // no diagnostic: init function
+}
+
+func Register(name string, v int) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = v
+}
+
+func Reset() {
+	registryMu.Lock()
+	registry = map[string]int{}
+	registryMu.Unlock()
+}
+
+func Lookup(name string) int {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return registry[name]
+}
+
+func UnsafeLookup(name string) int {
+	return registry[name] // want `global variable registry is accessed without holding registryMu`
+}
+
+func UnsafeReset() {
+	registry = nil // want `global variable registry is accessed without holding registryMu`
+}
+
+// --- Helpers that require the global lock from their callers ---
+
+func lookupLocked(name string) int {
+	return registry[name] // no diagnostic: requirement propagated to call sites
+}
+
+func clearLocked() {
+	registry = map[string]int{} // no diagnostic: requirement propagated to call sites
+}
+
+func SafeHelperCalls(name string) int {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	clearLocked()
+	return lookupLocked(name)
+}
+
+func UnsafeHelperCall(name string) int {
+	return lookupLocked(name) // want `registryMu must be held when calling lookupLocked\(\)`
+}
+
+func UnsafeZeroArgHelperCall() {
+	clearLocked() // want `registryMu must be held when calling clearLocked\(\)`
+}
+
+// --- Lock-state checks apply to package-level mutexes ---
+
+func DoubleLock() {
+	registryMu.Lock()
+	registryMu.Lock() // want `registryMu is already held when locking registryMu`
+	registryMu.Unlock()
+}
+
+func RegisterTwice(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	Register(name, 1) // want `registryMu is already held when calling Register\(\) which locks registryMu`
+}
+
+func LeakOnError(name string) error {
+	registryMu.Lock()
+	if name == "" {
+		return nil // want `return without unlocking registryMu \(locked at globals\.go:\d+:\d+\)`
+	}
+	registry[name] = 1
+	registryMu.Unlock()
+	return nil
+}
+
+// --- RWMutex guard: writes under RLock ---
+
+var (
+	cacheMu sync.RWMutex
+	cache   []string
+	hits    int
+)
+
+func Add(s string) {
+	cacheMu.Lock()
+	cache = append(cache, s)
+	hits = 0
+	cacheMu.Unlock()
+}
+
+func Get(i int) string {
+	cacheMu.RLock()
+	defer cacheMu.RUnlock()
+	hits++ // want `global variable hits is written while cacheMu is read-locked`
+	return cache[i]
+}
+
+// --- Globals written only at init are immutable ---
+
+var defaultNames = []string{"a", "b"}
+
+func Names() []string {
+	return defaultNames // no diagnostic: never written after initialization
+}
+
+// --- A map only mutated through its elements: updates and deletes are writes ---
+
+var (
+	sessionsMu sync.Mutex
+	sessions   = map[string]int{}
+)
+
+func Open(id string) {
+	sessionsMu.Lock()
+	sessions[id] = 1
+	sessionsMu.Unlock()
+}
+
+func Close(id string) {
+	sessionsMu.Lock()
+	delete(sessions, id)
+	sessionsMu.Unlock()
+}
+
+func UnsafeOpen(id string) {
+	sessions[id] = 1 // want `global variable sessions is accessed without holding sessionsMu`
+}
+
+func UnsafeClose(id string) {
+	delete(sessions, id) // want `global variable sessions is accessed without holding sessionsMu`
+}
@@ -0,0 +1,96 @@
+package goroutine_spawn
+
+import "sync"
+
+// --- Goroutine spawned while holding a lock (warning) ---
+
+type Worker struct {
+	id int
+}
+
+func (w *Worker) Run(tasks chan int) {
+	for range tasks {
+	}
+}
+
+type Pool struct {
+	mu      sync.Mutex
+	workers []*Worker
+	tasks   chan int
+}
+
+func (p *Pool) Scale(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := len(p.workers); i < n; i++ {
+		w := &Worker{id: i}
+		p.workers = append(p.workers, w)
+		go w.Run(p.tasks) // want `goroutine spawned while holding Pool\.mu`
+	}
+}
+
+// --- Spawned closure re-acquires the held lock (escalated) ---
+
+type Broadcaster struct {
+	mu        sync.Mutex
+	listeners []chan string
+}
+
+func (b *Broadcaster) Broadcast(msg string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	go func() { // want `goroutine spawned while holding Broadcaster\.mu also locks Broadcaster\.mu`
+		b.mu.Lock()
+		b.listeners = nil
+		b.mu.Unlock()
+	}()
+}
+
+// --- Spawned method re-acquires the held lock transitively (escalated) ---
+
+func (b *Broadcaster) addListener() {
+	b.mu.Lock()
+	b.listeners = append(b.listeners, make(chan string, 1))
+	b.mu.Unlock()
+}
+
+func (b *Broadcaster) grow() {
+	b.addListener()
+}
+
+func (b *Broadcaster) GrowAsync() {
+	b.mu.Lock()
+	go b.grow() // want `goroutine spawned while holding Broadcaster\.mu also locks Broadcaster\.mu`
+	b.mu.Unlock()
+}
+
+// --- Correct pattern: spawn after unlocking (no diagnostic) ---
+
+type SafeBroadcaster struct {
+	mu        sync.Mutex
+	listeners []chan string
+}
+
+func (b *SafeBroadcaster) Broadcast(msg string) {
+	b.mu.Lock()
+	snapshot := make([]chan string, len(b.listeners))
+	copy(snapshot, b.listeners)
+	b.mu.Unlock()
+
+	for _, ch := range snapshot {
+		go func(c chan string) { c <- msg }(ch)
+	}
+}
+
+// --- Suppressed with //mu:nolint ---
+
+func (p *Pool) ScaleOne() {
+	p.mu.Lock()
+	w := &Worker{id: len(p.workers)}
+	p.workers = append(p.workers, w)
+	//mu:nolint
+	go w.Run(p.tasks)
+	p.mu.Unlock()
+}
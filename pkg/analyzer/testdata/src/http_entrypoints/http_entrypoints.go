@@ -0,0 +1,107 @@
+package http_entrypoints
+
+import (
+	"log"
+	"net/http"
+	"sync"
+)
+
+type Stats struct {
+	mu       sync.Mutex
+	hits     int
+	misses   int
+	errors   int
+	requests int
+	served   int
+	inflight int
+}
+
+func (s *Stats) Reset() {
+	s.mu.Lock()
+	s.hits = 0
+	s.misses = 0
+	s.errors = 0
+	s.requests = 0
+	s.served = 0
+	s.inflight = 0
+	s.mu.Unlock()
+}
+
+// --- http.Handle with a handler type ---
+
+type hitsHandler struct {
+	stats *Stats
+}
+
+func (h *hitsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.stats.hits = 1 // want `field Stats\.hits is accessed without holding Stats\.mu`
+}
+
+func registerHits(s *Stats) {
+	http.Handle("/hits", &hitsHandler{stats: s})
+}
+
+// --- (*http.ServeMux).Handle with an http.HandlerFunc conversion ---
+
+func registerMisses(mux *http.ServeMux, s *Stats) {
+	mux.Handle("/misses", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.misses = 1 // want `field Stats\.misses is accessed without holding Stats\.mu`
+	}))
+}
+
+// --- http.HandlerFunc conversion of a named function ---
+
+var globalStats = &Stats{}
+
+func errorsHandler(w http.ResponseWriter, r *http.Request) {
+	globalStats.errors = 1 // want `field Stats\.errors is accessed without holding Stats\.mu`
+}
+
+func errorsRoute() http.Handler {
+	return http.HandlerFunc(errorsHandler)
+}
+
+// --- http.Server{Handler: h} ---
+
+func serve(s *Stats) error {
+	srv := &http.Server{
+		Addr: ":8080",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s.requests = 1 // want `field Stats\.requests is accessed without holding Stats\.mu`
+		}),
+	}
+	return srv.ListenAndServe()
+}
+
+// --- Middleware constructors ---
+
+func logging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Println(r.URL.Path)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func counting(s *Stats, next func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.inflight = 1 // want `field Stats\.inflight is accessed without holding Stats\.mu`
+		next(w, r)
+	}
+}
+
+func served(s *Stats) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.served = 1 // want `field Stats\.served is accessed without holding Stats\.mu`
+	}
+}
+
+func registerMiddleware(mux *http.ServeMux, s *Stats) {
+	mux.Handle("/served", logging(http.HandlerFunc(counting(s, served(s)))))
+}
+
+// --- Not a handler: no concurrent context ---
+
+func configure(s *Stats) {
+	s.hits = 0
+	s.misses = 0
+}
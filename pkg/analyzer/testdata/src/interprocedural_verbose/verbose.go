@@ -99,3 +99,47 @@ func (m *Multi) touchAll() {
 func (m *Multi) UnsafeMulti() {
 	m.touchAll() // want `Multi\.mu must be held when calling touchAll\(\)\n\ttouchAll\(\) accesses Multi\.\w+ at verbose\.go:\d+:\d+\n\n\ttouchAll\(\) accesses Multi\.\w+ at verbose\.go:\d+:\d+`
 }
+
+// --- Blocking chain: caller holds lock, callee blocks transitively ---
+
+type Queue struct {
+	mu    sync.Mutex
+	items []int
+	ready chan int
+}
+
+func (q *Queue) signal(v int) {
+	q.ready <- v
+}
+
+func (q *Queue) publish(v int) {
+	q.signal(v)
+}
+
+func (q *Queue) Add(v int) {
+	q.mu.Lock()
+	q.items = append(q.items, v)
+	q.publish(v) // want `Queue\.mu is held when calling publish\(\) which may block\n\tpublish\(\) calls signal\(\) at verbose\.go:\d+:\d+\n\tsignal\(\) blocks on channel send at verbose\.go:\d+:\d+`
+	q.mu.Unlock()
+}
+
+// --- Package-level guard: requirement from a global access ---
+
+var (
+	statsMu sync.Mutex
+	stats   map[string]int
+)
+
+func ResetStats() {
+	statsMu.Lock()
+	stats = make(map[string]int)
+	statsMu.Unlock()
+}
+
+func bump(name string) {
+	stats[name]++
+}
+
+func UnsafeBump(name string) {
+	bump(name) // want `statsMu must be held when calling bump\(\)\n\tbump\(\) accesses stats at verbose\.go:\d+:\d+`
+}
@@ -0,0 +1,236 @@
+package lock_contracts
+
+import "sync"
+
+type Cache struct {
+	mu    sync.Mutex
+	items map[string]string
+	hits  int
+}
+
+func (c *Cache) Get(k string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hits++
+	return c.items[k]
+}
+
+func (c *Cache) Set(k, v string) {
+	c.mu.Lock()
+	c.items[k] = v
+	c.mu.Unlock()
+}
+
+// --- //mu:requires: the body runs with the lock held, callers must hold it ---
+
+// evictLocked removes k.
+//
+//mu:requires c.mu
+func (c *Cache) evictLocked(k string) {
+	delete(c.items, k)
+	c.hits = 0
+}
+
+func (c *Cache) Evict(k string) {
+	c.mu.Lock()
+	c.evictLocked(k)
+	c.mu.Unlock()
+}
+
+func (c *Cache) EvictUnlocked(k string) {
+	c.evictLocked(k) // want `Cache\.mu must be held when calling evictLocked\(\)`
+}
+
+// The contract replaces inference: an access needing a lock the contract does
+// not declare is reported in the body instead of becoming a requirement.
+
+type Pair struct {
+	mu    sync.Mutex
+	a     int
+	bMu   sync.Mutex
+	b     int
+	other *Cache
+}
+
+func (p *Pair) SetA(v int) {
+	p.mu.Lock()
+	p.a = v
+	p.mu.Unlock()
+}
+
+func (p *Pair) SetB(v int) {
+	p.bMu.Lock()
+	p.b = v
+	p.bMu.Unlock()
+}
+
+func (p *Pair) B() int {
+	p.bMu.Lock()
+	defer p.bMu.Unlock()
+	return p.b
+}
+
+func (p *Pair) IncB() {
+	p.bMu.Lock()
+	p.b++
+	p.bMu.Unlock()
+}
+
+//mu:requires p.mu
+func (p *Pair) swapLocked() {
+	p.a, p.b = p.b, p.a // want `field Pair\.b is accessed without holding Pair\.bMu` `field Pair\.b is accessed without holding Pair\.bMu`
+}
+
+func (p *Pair) Swap() {
+	p.mu.Lock()
+	p.swapLocked()
+	p.mu.Unlock()
+}
+
+// A required lock released by the body contradicts the contract.
+
+//mu:requires c.mu
+func (c *Cache) resetLocked() {
+	c.hits = 0
+	c.mu.Unlock()
+	return // want `resetLocked\(\) returns without holding c\.mu despite //mu:requires c\.mu \x{2014} use //mu:releases`
+}
+
+func (c *Cache) Reset() {
+	c.mu.Lock()
+	c.resetLocked()
+}
+
+// --- //mu:acquires: the lock is held on return, callers must release it ---
+
+//mu:acquires c.mu
+func (c *Cache) lock() {
+	c.mu.Lock()
+}
+
+func (c *Cache) Len() int {
+	c.lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+func (c *Cache) Forgets() {
+	c.lock() // want `Forgets\(\) calls lock\(\) which acquires Cache\.mu, but Forgets\(\) never releases it`
+}
+
+//mu:acquires c.mu
+func (c *Cache) tryLock(ok bool) bool {
+	if !ok {
+		return false // want `tryLock\(\) returns without holding c\.mu despite //mu:acquires c\.mu`
+	}
+	c.mu.Lock()
+	return true
+}
+
+// --- //mu:releases: the caller's lock is released by the callee ---
+
+//mu:releases c.mu
+func (c *Cache) unlock() {
+	c.hits++
+	c.mu.Unlock()
+}
+
+func (c *Cache) Touch() {
+	c.mu.Lock()
+	c.hits++
+	c.unlock() // the caller no longer holds c.mu: no lock leak
+}
+
+//mu:releases c.mu
+func (c *Cache) keep() {
+	c.hits++
+	return // want `keep\(\) returns holding c\.mu despite //mu:releases c\.mu`
+}
+
+//mu:releases c.mu
+func (c *Cache) keepImplicit() {
+	c.hits++
+} // want `keepImplicit\(\) returns holding c\.mu despite //mu:releases c\.mu`
+
+func (c *Cache) Keep() {
+	c.mu.Lock()
+	c.keep()
+	c.mu.Lock()
+	c.keepImplicit()
+}
+
+// Contracts compose: the lock acquired by an acquires helper is held for a
+// requires function, then released by a releases function.
+
+//mu:requires c.mu
+func (c *Cache) incLocked() {
+	c.hits++
+}
+
+func (c *Cache) Inc() {
+	c.lock()
+	c.incLocked()
+	c.unlock()
+}
+
+// --- Package-level mutexes ---
+
+var (
+	regMu    sync.Mutex
+	registry = map[string]int{}
+)
+
+func Register(k string, v int) {
+	regMu.Lock()
+	registerLocked(k, v)
+	regMu.Unlock()
+}
+
+//mu:requires regMu
+func registerLocked(k string, v int) {
+	registry[k] = v
+}
+
+func Lookup(k string) int {
+	regMu.Lock()
+	defer regMu.Unlock()
+	return registry[k]
+}
+
+// --- Invalid contracts ---
+
+//mu:requires c.nope // want `//mu:requires directive: c\.nope is not a mutex field of a pointer parameter or a package-level mutex`
+func (c *Cache) badField() {}
+
+//mu:requires // want `//mu:requires directive names no mutex`
+func (c *Cache) noMutex() {}
+
+func (c *Cache) misplaced() {
+	//mu:requires c.mu // want `//mu:requires directive must be in the doc comment of a function`
+	_ = c
+}
+
+func main() {
+	c := &Cache{items: map[string]string{}}
+	p := &Pair{}
+	go c.Evict("")
+	go c.EvictUnlocked("")
+	go p.Swap()
+	go p.SetA(1)
+	go p.SetB(1)
+	go p.B()
+	go p.IncB()
+	go c.Reset()
+	go c.Len()
+	go c.Forgets()
+	go c.tryLock(true)
+	go c.Touch()
+	go c.Keep()
+	go c.Inc()
+	go c.Set("", "")
+	go Register("", 0)
+	go Lookup("")
+	go c.badField()
+	go c.noMutex()
+	go c.misplaced()
+}
@@ -0,0 +1,114 @@
+package lock_wrappers
+
+import "sync"
+
+// --- Method wrapper: the callback runs with s.mu held ---
+
+type Store struct {
+	mu    sync.RWMutex
+	items map[string]int
+	count int
+}
+
+func (s *Store) withLock(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn()
+}
+
+func (s *Store) withRLock(fn func()) {
+	s.mu.RLock()
+	fn()
+	s.mu.RUnlock()
+}
+
+// All writes happen inside withLock callbacks: the guard is still inferred.
+func (s *Store) Set(k string, v int) {
+	s.withLock(func() {
+		s.items[k] = v // no diagnostic: callback runs under s.mu
+		s.count++      // no diagnostic: callback runs under s.mu
+	})
+}
+
+func (s *Store) Get(k string) int {
+	var v int
+	s.withRLock(func() {
+		v = s.items[k] // no diagnostic: callback runs under s.mu (read-locked)
+	})
+	return v
+}
+
+func (s *Store) Count() int {
+	return s.count // want `field Store\.count is accessed without holding Store\.mu`
+}
+
+func (s *Store) BumpUnderRLock() {
+	s.withRLock(func() {
+		s.count++ // want `field Store\.count is written while Store\.mu is read-locked`
+	})
+}
+
+func (s *Store) Relock() {
+	s.withLock(func() {
+		s.mu.Lock() // want `Store\.mu is already held when locking Store\.mu`
+		s.mu.Unlock()
+	})
+}
+
+// --- Method value passed to a wrapper ---
+
+func (s *Store) resetLocked() {
+	s.items = map[string]int{} // no diagnostic: requirement propagated to call sites
+	s.count = 0                // no diagnostic: requirement propagated to call sites
+}
+
+func (s *Store) Reset() {
+	s.withLock(s.resetLocked) // no diagnostic: resetLocked runs under s.mu
+}
+
+func (s *Store) UnsafeReset() {
+	s.resetLocked() // want `Store\.mu must be held when calling resetLocked\(\)`
+}
+
+// --- Package-level wrapper ---
+
+var (
+	registryMu sync.Mutex
+	registry   map[string]string
+)
+
+func withRegistry(fn func()) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	fn()
+}
+
+func Register(name, v string) {
+	withRegistry(func() {
+		registry[name] = v // no diagnostic: callback runs under registryMu
+	})
+}
+
+func ResetRegistry() {
+	withRegistry(func() {
+		registry = map[string]string{} // no diagnostic: callback runs under registryMu
+	})
+}
+
+func Lookup(name string) string {
+	return registry[name] // want `global variable registry is accessed without holding registryMu`
+}
+
+// --- Higher-order function without a lock: callbacks are unaffected ---
+
+func each(xs []int, fn func(int)) {
+	for _, x := range xs {
+		fn(x)
+	}
+}
+
+func (s *Store) AddAll(xs []int) {
+	each(xs, func(x int) {
+		s.count += x // want `field Store\.count is accessed without holding Store\.mu` `field Store\.count is accessed without holding Store\.mu`
+	})
+}
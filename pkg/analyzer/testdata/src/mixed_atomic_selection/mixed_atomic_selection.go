@@ -0,0 +1,33 @@
+package mixed_atomic_selection
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Run with -checks=-C16: mixed atomic access is not reported, ordinary
+// unguarded access (C1) still is.
+
+type Conn struct {
+	mu     sync.Mutex
+	closed int32
+	buf    []byte
+}
+
+func (c *Conn) Close() {
+	atomic.StoreInt32(&c.closed, 1)
+}
+
+func (c *Conn) IsClosed() bool {
+	return c.closed == 1 // no diagnostic: C16 is disabled
+}
+
+func (c *Conn) Write(b []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.buf = append(c.buf, b...)
+}
+
+func (c *Conn) Reset() {
+	c.buf = nil // want `field Conn\.buf is accessed without holding Conn\.mu`
+}
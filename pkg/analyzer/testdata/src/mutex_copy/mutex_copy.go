@@ -0,0 +1,96 @@
+package mutex_copy
+
+import (
+	"fmt"
+	"sync"
+)
+
+type Counter struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (c *Counter) Inc() {
+	c.mu.Lock()
+	c.count++
+	c.mu.Unlock()
+}
+
+// --- Value receiver that locks the copy ---
+
+func (c Counter) Get() int { // want `Counter\.mu copied here; locking the copy does not protect Counter\.count`
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count
+}
+
+// --- Range-by-value over []Counter ---
+
+func Total(counters []Counter) int {
+	total := 0
+	for _, c := range counters { // want `Counter\.mu copied here; locking the copy does not protect Counter\.count`
+		c.mu.Lock()
+		total += c.count
+		c.mu.Unlock()
+	}
+	return total
+}
+
+// Range by index locks the original — no diagnostic.
+func TotalByIndex(counters []Counter) int {
+	total := 0
+	for i := range counters {
+		c := &counters[i]
+		c.mu.Lock()
+		total += c.count
+		c.mu.Unlock()
+	}
+	return total
+}
+
+// --- Returning Counter by value from a locked getter ---
+
+func (c *Counter) Snapshot() Counter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return *c // want `Counter\.mu copied here; locking the copy does not protect Counter\.count`
+}
+
+// Returning a fresh value is not a copy — no diagnostic.
+func (c *Counter) Fresh() Counter {
+	return Counter{}
+}
+
+// --- Copying through an interface ---
+
+func (c *Counter) Describe() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return fmt.Sprint(*c) // want `Counter\.mu copied here; locking the copy does not protect Counter\.count`
+}
+
+// --- Struct with no inferred guard ---
+
+type Registry struct {
+	rw    sync.RWMutex
+	items map[string]int
+}
+
+func (r Registry) Len() int { // want `Registry\.rw copied here; locking the copy does not protect the original Registry`
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+	return len(r.items)
+}
+
+// --- Value receiver that never locks — no diagnostic from golintmu ---
+
+func (c Counter) Name() string {
+	return "counter"
+}
+
+// --- Suppressed with //mu:nolint ---
+
+func (c *Counter) Clone() Counter {
+	//mu:nolint
+	return *c
+}
@@ -0,0 +1,144 @@
+package once
+
+import "sync"
+
+// --- Lazy initialization with once.Do(closure) ---
+
+type Cache struct {
+	mu      sync.Mutex
+	once    sync.Once
+	entries map[string]string
+	hits    int
+}
+
+func (c *Cache) init() map[string]string {
+	c.once.Do(func() {
+		c.entries = make(map[string]string) // no diagnostic: synchronized by once
+	})
+	return c.entries // no diagnostic: entries is only written inside the Once callback
+}
+
+func (c *Cache) Get(key string) string {
+	c.init()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hits++
+	return c.entries[key]
+}
+
+func (c *Cache) Hits() int {
+	return c.hits // want `field Cache\.hits is accessed without holding Cache\.mu`
+}
+
+// --- Lazy initialization with once.Do(method value) ---
+
+type Config struct {
+	mu     sync.Mutex
+	once   sync.Once
+	values map[string]string
+	dirty  bool
+}
+
+func (c *Config) load() {
+	c.values = map[string]string{"k": "v"} // no diagnostic: Once callback
+}
+
+func (c *Config) Lookup(key string) string {
+	c.once.Do(c.load)
+	return c.values[key]
+}
+
+func (c *Config) MarkDirty() {
+	c.mu.Lock()
+	c.dirty = len(c.values) > 0
+	c.mu.Unlock()
+}
+
+// --- sync.OnceValue / sync.OnceFunc ---
+
+type Resolver struct {
+	mu      sync.Mutex
+	addr    string
+	port    int
+	pending int
+	resolve func() string
+	reset   func()
+}
+
+func (r *Resolver) Setup() {
+	r.mu.Lock()
+	r.pending++
+	r.resolve = sync.OnceValue(func() string {
+		r.addr = "127.0.0.1" // no diagnostic: OnceValue callback
+		return r.addr
+	})
+	r.reset = sync.OnceFunc(func() {
+		r.port = 8080 // no diagnostic: OnceFunc callback
+	})
+	r.mu.Unlock()
+}
+
+func (r *Resolver) Pending() (int, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pending, r.addr
+}
+
+func (r *Resolver) Addr() string {
+	return r.addr
+}
+
+func (r *Resolver) Port() int {
+	return r.port
+}
+
+// --- Field written both in Once and under lock: still guarded ---
+
+type Registry struct {
+	mu    sync.Mutex
+	once  sync.Once
+	items []string
+}
+
+func (reg *Registry) ensure() {
+	reg.once.Do(func() {
+		reg.items = []string{} // no diagnostic: Once callback
+	})
+}
+
+func (reg *Registry) Add(item string) {
+	reg.ensure()
+	reg.mu.Lock()
+	reg.items = append(reg.items, item)
+	reg.mu.Unlock()
+}
+
+func (reg *Registry) Len() int {
+	return len(reg.items) // want `field Registry\.items is accessed without holding Registry\.mu`
+}
+
+// --- Method passed to once.Do but also called directly: not initialization ---
+
+type Pool struct {
+	mu    sync.Mutex
+	once  sync.Once
+	conns []string
+}
+
+func (p *Pool) reset() {
+	p.conns = nil
+}
+
+func (p *Pool) Start() {
+	p.once.Do(p.reset)
+}
+
+func (p *Pool) Close() {
+	p.reset() // want `Pool\.mu must be held when calling reset\(\)`
+}
+
+func (p *Pool) Add(c string) {
+	p.mu.Lock()
+	p.conns = append(p.conns, c)
+	p.mu.Unlock()
+}
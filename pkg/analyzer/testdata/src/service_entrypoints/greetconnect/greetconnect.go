@@ -0,0 +1,18 @@
+// Package greetconnect mimics the code generated by protoc-gen-connect-go.
+package greetconnect
+
+import "net/http"
+
+type Request struct{ Name string }
+
+type Response struct{ Count int }
+
+// CounterServiceHandler is an implementation of the CounterService service.
+type CounterServiceHandler interface {
+	Increment(*Request) (*Response, error)
+}
+
+// NewCounterServiceHandler builds an HTTP handler from the service implementation.
+func NewCounterServiceHandler(svc CounterServiceHandler, opts ...any) (string, http.Handler) {
+	return "/counter.v1.CounterService/", nil
+}
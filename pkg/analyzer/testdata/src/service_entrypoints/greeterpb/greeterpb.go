@@ -0,0 +1,44 @@
+// Package greeterpb mimics the code generated by protoc-gen-go-grpc.
+package greeterpb
+
+type HelloRequest struct{ Name string }
+
+type HelloReply struct{ Message string }
+
+type ServiceRegistrar interface {
+	RegisterService(desc any, impl any)
+}
+
+// GreeterServer is the server API for Greeter service.
+type GreeterServer interface {
+	SayHello(*HelloRequest) (*HelloReply, error)
+	mustEmbedUnimplementedGreeterServer()
+}
+
+// UnimplementedGreeterServer must be embedded to have forward compatible implementations.
+type UnimplementedGreeterServer struct{}
+
+func (UnimplementedGreeterServer) SayHello(*HelloRequest) (*HelloReply, error) { return nil, nil }
+func (UnimplementedGreeterServer) mustEmbedUnimplementedGreeterServer()        {}
+
+func RegisterGreeterServer(s ServiceRegistrar, srv GreeterServer) {
+	s.RegisterService(nil, srv)
+}
+
+// StoreServer is the server API for Store service.
+type StoreServer interface {
+	Get(*HelloRequest) (*HelloReply, error)
+	Put(*HelloRequest) (*HelloReply, error)
+	mustEmbedUnimplementedStoreServer()
+}
+
+// UnimplementedStoreServer must be embedded to have forward compatible implementations.
+type UnimplementedStoreServer struct{}
+
+func (UnimplementedStoreServer) Get(*HelloRequest) (*HelloReply, error) { return nil, nil }
+func (UnimplementedStoreServer) Put(*HelloRequest) (*HelloReply, error) { return nil, nil }
+func (UnimplementedStoreServer) mustEmbedUnimplementedStoreServer()     {}
+
+func RegisterStoreServer(s ServiceRegistrar, srv StoreServer) {
+	s.RegisterService(nil, srv)
+}
@@ -0,0 +1,101 @@
+package service_entrypoints
+
+import (
+	"net/http"
+	"sync"
+
+	"service_entrypoints/greetconnect"
+	"service_entrypoints/greeterpb"
+)
+
+// --- gRPC: implementation passed to RegisterGreeterServer ---
+
+type Greeter struct {
+	greeterpb.UnimplementedGreeterServer
+
+	mu    sync.Mutex
+	count int
+}
+
+func (g *Greeter) lockedInc() {
+	g.mu.Lock()
+	g.count++
+	g.mu.Unlock()
+}
+
+func (g *Greeter) SayHello(req *greeterpb.HelloRequest) (*greeterpb.HelloReply, error) {
+	g.count++ // want `field Greeter\.count is accessed without holding Greeter\.mu` `field Greeter\.count is accessed without holding Greeter\.mu`
+	return &greeterpb.HelloReply{Message: req.Name}, nil
+}
+
+// reset is not a service method: no concurrent context.
+func (g *Greeter) reset() {
+	g.count = 0
+}
+
+func register(s greeterpb.ServiceRegistrar) {
+	greeterpb.RegisterGreeterServer(s, &Greeter{})
+}
+
+// --- gRPC: implementation embedding UnimplementedStoreServer, registered elsewhere ---
+
+type Store struct {
+	greeterpb.UnimplementedStoreServer
+
+	mu    sync.Mutex
+	items map[string]string
+}
+
+func (s *Store) Put(req *greeterpb.HelloRequest) (*greeterpb.HelloReply, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.items == nil {
+		s.items = make(map[string]string)
+	}
+	s.items[req.Name] = req.Name
+	return &greeterpb.HelloReply{}, nil
+}
+
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+// Get is a service method even though it is not registered in this package.
+func (s *Store) Get(req *greeterpb.HelloRequest) (*greeterpb.HelloReply, error) {
+	return &greeterpb.HelloReply{Message: s.lookup(req.Name)}, nil // want `Store\.mu must be held when calling lookup\(\)`
+}
+
+// lookup is reached from a service method.
+func (s *Store) lookup(name string) string {
+	return s.items[name]
+}
+
+// --- ConnectRPC: implementation passed to NewCounterServiceHandler ---
+
+type Counter struct {
+	mu    sync.Mutex
+	total int
+}
+
+func (c *Counter) Add(n int) {
+	c.mu.Lock()
+	c.total += n
+	c.mu.Unlock()
+}
+
+func (c *Counter) Increment(req *greetconnect.Request) (*greetconnect.Response, error) {
+	c.total++ // want `field Counter\.total is accessed without holding Counter\.mu` `field Counter\.total is accessed without holding Counter\.mu`
+	return &greetconnect.Response{}, nil
+}
+
+// snapshot is not a service method: no concurrent context.
+func (c *Counter) snapshot() int {
+	return c.total
+}
+
+func mount(mux *http.ServeMux) {
+	path, handler := greetconnect.NewCounterServiceHandler(&Counter{})
+	mux.Handle(path, handler)
+}
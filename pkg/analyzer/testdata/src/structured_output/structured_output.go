@@ -0,0 +1,89 @@
+package structured_output
+
+import "sync"
+
+// --- C1: direct field access without the inferred guard ---
+
+type Counter struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (c *Counter) Inc() {
+	c.mu.Lock()
+	c.count++
+	c.mu.Unlock()
+}
+
+func (c *Counter) Peek() int {
+	return c.count // want `field Counter\.count is accessed without holding Counter\.mu`
+}
+
+// --- C1 at a call site: provenance chain through two helpers ---
+
+type Cache struct {
+	mu    sync.Mutex
+	items map[string]string
+}
+
+func (c *Cache) Get(k string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.items[k]
+}
+
+func (c *Cache) reset() {
+	c.items = nil
+}
+
+func (c *Cache) purge() {
+	c.reset()
+}
+
+func (c *Cache) Clear() {
+	c.purge() // want `Cache\.mu must be held when calling purge\(\)\n\tpurge\(\) calls reset\(\) at structured_output\.go:\d+:\d+\n\treset\(\) accesses Cache\.items at structured_output\.go:\d+:\d+`
+}
+
+// --- C5: lock leak, related to the acquire position ---
+
+func (c *Counter) Reset(skip bool) {
+	c.mu.Lock()
+	if skip {
+		return // want `return without unlocking Counter\.mu \(locked at structured_output\.go:\d+:\d+\)`
+	}
+	c.count = 0
+	c.mu.Unlock()
+}
+
+// --- C3: lock-order cycle, related to every edge ---
+
+func Transfer(a *Counter, b *Cache) {
+	a.mu.Lock()
+	b.mu.Lock()
+	b.mu.Unlock()
+	a.mu.Unlock()
+}
+
+func Refund(a *Counter, b *Cache) {
+	b.mu.Lock()
+	a.mu.Lock() // want `potential deadlock: lock ordering cycle between Cache\.mu and Counter\.mu`
+	a.mu.Unlock()
+	b.mu.Unlock()
+}
+
+// --- C1 on a package-level variable ---
+
+var (
+	hitsMu sync.Mutex
+	hits   int
+)
+
+func Hit() {
+	hitsMu.Lock()
+	hits++
+	hitsMu.Unlock()
+}
+
+func Hits() int {
+	return hits // want `global variable hits is accessed without holding hitsMu`
+}
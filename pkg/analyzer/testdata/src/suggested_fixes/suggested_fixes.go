@@ -0,0 +1,117 @@
+package suggested_fixes
+
+import "sync"
+
+type Store struct {
+	rw   sync.RWMutex
+	data map[string]string
+}
+
+func (s *Store) Put(k, v string) {
+	s.rw.Lock()
+	s.data[k] = v
+	s.rw.Unlock()
+}
+
+// --- C6: mismatched unlock, the method is swapped ---
+
+func (s *Store) Get(k string) string {
+	s.rw.RLock()
+	defer s.rw.Unlock() // want `Store\.rw is read-locked but Unlock\(\) was called`
+	return s.data[k]
+}
+
+func (s *Store) Delete(k string) {
+	s.rw.Lock()
+	delete(s.data, k)
+	s.rw.RUnlock() // want `Store\.rw is exclusively locked but RUnlock\(\) was called`
+}
+
+// --- C7: deferred Lock instead of Unlock ---
+
+type Counter struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (c *Counter) Inc() {
+	c.mu.Lock()
+	c.count++
+	c.mu.Unlock()
+}
+
+func (c *Counter) Value() int {
+	c.mu.Lock()
+	defer c.mu.Lock() // want `defer Counter\.mu\.Lock\(\) will deadlock`
+	return c.count
+}
+
+// --- C5: lock leak without manual unlock, a deferred unlock is added ---
+
+func (c *Counter) IncIf(cond bool) int {
+	if cond {
+		c.mu.Lock()
+		c.count++
+		return c.count // want `return without unlocking Counter\.mu`
+	}
+	return 0
+}
+
+// C5 with a manual unlock on another path: no fix (a deferred unlock would
+// unlock twice).
+func (c *Counter) Add(n int) {
+	c.mu.Lock()
+	if n == 0 {
+		return // want `return without unlocking Counter\.mu`
+	}
+	c.count += n
+	c.mu.Unlock()
+}
+
+// --- C1: the access is wrapped in Lock/Unlock ---
+
+func (c *Counter) Reset() {
+	c.count = 0 // want `field Counter\.count is accessed without holding Counter\.mu`
+}
+
+// C1 in a return statement: no fix.
+func (c *Counter) Peek() int {
+	return c.count // want `field Counter\.count is accessed without holding Counter\.mu`
+}
+
+// C1 in a function holding another lock: no fix.
+func (c *Counter) CopyFrom(s *Store) {
+	s.rw.RLock()
+	c.count = len(s.data) // want `field Counter\.count is accessed without holding Counter\.mu`
+	s.rw.RUnlock()
+}
+
+// C1 in a statement calling a method that locks the mutex: no fix (holding
+// the mutex around the call would deadlock).
+func (c *Counter) next() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.count++
+	return c.count
+}
+
+func (c *Counter) Renumber() {
+	c.count = c.next() // want `field Counter\.count is accessed without holding Counter\.mu`
+}
+
+// --- C1 on a package-level variable ---
+
+var (
+	hitsMu sync.Mutex
+	hits   int
+)
+
+func Hit() {
+	hitsMu.Lock()
+	hits++
+	hitsMu.Unlock()
+}
+
+func ResetHits() {
+	hits = 0 // want `global variable hits is accessed without holding hitsMu`
+}
@@ -0,0 +1,76 @@
+package suppression_directives
+
+import "sync"
+
+// Run with -require-reason.
+
+type Counter struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (c *Counter) Inc() {
+	c.mu.Lock()
+	c.count++
+	c.mu.Unlock()
+}
+
+type Registry struct {
+	mu sync.Mutex
+}
+
+// --- //mu:nolint:C5 silences the lock leak, not the C1 on the same line ---
+
+func (r *Registry) Peek(c *Counter, fast bool) int {
+	r.mu.Lock()
+	if fast {
+		//mu:nolint:C5 // unlocked by the caller on the fast path
+		return c.count // want `field Counter\.count is accessed without holding Counter\.mu`
+	}
+	r.mu.Unlock()
+	return 0
+}
+
+// --- Trailing comments silence their own line only ---
+
+func (c *Counter) Reset() {
+	c.count = 0 //mu:nolint:C1 // reset is only called before Start
+	c.count = 1 // want `field Counter\.count is accessed without holding Counter\.mu`
+}
+
+func (c *Counter) Get() int {
+	return c.count //mu:nolint // benign race on a statistic
+}
+
+// --- Several checks in one directive ---
+
+func (r *Registry) Drain(c *Counter, fast bool) int {
+	r.mu.Lock()
+	if fast {
+		//mu:nolint:C1,C5 // drained under the registry lock
+		return c.count
+	}
+	r.mu.Unlock()
+	return 0
+}
+
+// --- //mu:ignore:C1 keeps the other checks of the function ---
+
+//mu:ignore:C1 // legacy accessor
+func (c *Counter) Twice() int {
+	c.mu.Lock()
+	c.mu.Lock() // want `Counter\.mu is already held`
+	c.mu.Unlock()
+	return c.count
+}
+
+// --- Invalid directives ---
+
+func (c *Counter) Unknown() int {
+	//mu:nolint:C99 // want `//mu:nolint:C99 directive names unknown check "C99"`
+	return c.count // want `field Counter\.count is accessed without holding Counter\.mu`
+}
+
+func (c *Counter) NoReason() int {
+	return c.count /* want `//mu:nolint:C1 directive has no reason \x{2014} explain why` */ //mu:nolint:C1
+}
@@ -0,0 +1,57 @@
+package unused_suppressions
+
+import "sync"
+
+// Run with -report-unused-suppressions.
+
+type Counter struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (c *Counter) Inc() {
+	c.mu.Lock()
+	c.count++
+	c.mu.Unlock()
+}
+
+// --- Used directives are not reported ---
+
+func (c *Counter) Peek() int {
+	//mu:nolint:C1 // statistics only
+	return c.count
+}
+
+//mu:ignore // debugging helper
+func (c *Counter) Dump() int {
+	return c.count
+}
+
+// --- Directives suppressing nothing ---
+
+func (c *Counter) Get() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	//mu:nolint // want `//mu:nolint directive suppresses no diagnostic`
+	return c.count
+}
+
+func (c *Counter) Set(v int) {
+	c.mu.Lock()
+	c.count = v /* want `//mu:nolint:C1 directive suppresses no diagnostic` */ //mu:nolint:C1 // fixed since
+	c.mu.Unlock()
+}
+
+//mu:ignore:C5 // want `//mu:ignore:C5 directive suppresses no diagnostic`
+func (c *Counter) Reset() {
+	c.mu.Lock()
+	c.count = 0
+	c.mu.Unlock()
+}
+
+// --- A directive for another check does not hide the finding, and is unused ---
+
+func (c *Counter) Load() int {
+	//mu:nolint:C5 // want `//mu:nolint:C5 directive suppresses no diagnostic`
+	return c.count // want `field Counter\.count is accessed without holding Counter\.mu`
+}
@@ -0,0 +1,23 @@
+package app
+
+import "whole_program/store"
+
+func Record(s *store.Stats, hit bool) {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+	if hit {
+		s.Hits++
+	} else {
+		s.Misses++
+	}
+}
+
+func HitRate(s *store.Stats) float64 {
+	total := s.Hits + s.Misses // want `field Stats\.Hits is accessed without holding Stats\.Mu` `field Stats\.Misses is accessed without holding Stats\.Mu`
+	if total == 0 {
+		return 0
+	}
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+	return float64(s.Hits) / float64(total)
+}
@@ -0,0 +1,21 @@
+package legacy
+
+import "sync"
+
+// golintmu.json in this directory disables C1 for this package only: the
+// other packages of the program keep their own configuration.
+
+type Counter struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (c *Counter) Inc() {
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+}
+
+func (c *Counter) Peek() int {
+	return c.n // no finding: C1 is disabled
+}
@@ -0,0 +1,15 @@
+package store
+
+import "sync"
+
+// Stats is locked by its users: the package itself never accesses the
+// counters, so their guard is inferred from the accesses of package app.
+type Stats struct {
+	Mu     sync.Mutex
+	Hits   int // want `field Stats\.Hits is guarded by Stats\.Mu but is exported`
+	Misses int // want `field Stats\.Misses is guarded by Stats\.Mu but is exported`
+}
+
+func NewStats() *Stats {
+	return &Stats{}
+}
@@ -0,0 +1,233 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// The go/analysis driver analyzes one package at a time, bottom-up: the guard
+// of a type is inferred from the accesses of its own package, exported as a
+// FieldGuardFact, and the accesses of its importers can only be checked
+// against it. Whole-program mode builds a single SSA program for a set of
+// packages and runs the phases over all of them at once, as if they were one
+// package: the observations of every importer feed guard inference, and the
+// concurrent entrypoints of a command reach the library functions it calls.
+// Facts are neither imported nor exported. Each package keeps the
+// configuration of its own directory, as in the per-package analyzer.
+
+// RunWholeProgram analyzes pkgs, loaded by go/packages with syntax and type
+// information (packages.LoadAllSyntax), as a single program, and returns the
+// findings. The dependencies of pkgs are not analyzed. Test variants must not
+// be included: they are copies of their package, distinct from the package
+// the importers see.
+func RunWholeProgram(pkgs []*packages.Package) ([]Finding, error) {
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+	for _, p := range pkgs {
+		if p.Types == nil || p.TypesInfo == nil || p.IllTyped {
+			return nil, fmt.Errorf("%s: package has no type information", p.PkgPath)
+		}
+	}
+
+	prog, ssaPkgs := ssautil.Packages(pkgs, 0)
+	prog.Build()
+
+	info := &types.Info{
+		Types:        make(map[ast.Expr]types.TypeAndValue),
+		Instances:    make(map[*ast.Ident]types.Instance),
+		Defs:         make(map[*ast.Ident]types.Object),
+		Uses:         make(map[*ast.Ident]types.Object),
+		Implicits:    make(map[ast.Node]types.Object),
+		Selections:   make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:       make(map[ast.Node]*types.Scope),
+		FileVersions: make(map[*ast.File]string),
+	}
+	var files []*ast.File
+	var srcFuncs []*ssa.Function
+	localPkgs := make(map[*types.Package]bool)
+	filePkgs := make(map[*token.File]*types.Package)
+	var typesPkgs []*types.Package
+	configs := make(map[*types.Package]*config)
+	for _, p := range pkgs {
+		cfg, err := filesConfig(p.Fset, p.Syntax)
+		if err != nil {
+			return nil, err
+		}
+		configs[p.Types] = cfg
+		mergeTypesInfo(info, p.TypesInfo)
+		files = append(files, p.Syntax...)
+		srcFuncs = append(srcFuncs, sourceFunctions(prog, p)...)
+		localPkgs[p.Types] = true
+		typesPkgs = append(typesPkgs, p.Types)
+		for _, f := range p.Syntax {
+			if tf := p.Fset.File(f.Pos()); tf != nil {
+				filePkgs[tf] = p.Types
+			}
+		}
+	}
+
+	pass := &analysis.Pass{
+		Analyzer:          &analysis.Analyzer{Name: Analyzer.Name, Doc: Analyzer.Doc}, // no FactTypes: facts are not used
+		Fset:              pkgs[0].Fset,
+		Files:             files,
+		Pkg:               pkgs[0].Types,
+		TypesInfo:         info,
+		TypesSizes:        pkgs[0].TypesSizes,
+		Report:            func(analysis.Diagnostic) {},
+		ReadFile:          os.ReadFile,
+		ImportObjectFact:  func(types.Object, analysis.Fact) bool { return false },
+		ExportObjectFact:  func(types.Object, analysis.Fact) {},
+		ImportPackageFact: func(*types.Package, analysis.Fact) bool { return false },
+		ExportPackageFact: func(analysis.Fact) {},
+	}
+	baseline, err := loadBaseline(baselinePath)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := newPassContext(pass, ssaPkgs[0], srcFuncs, configs[pkgs[0].Types], baseline)
+	ctx.pkgs = typesPkgs
+	ctx.localPkgs = localPkgs
+	ctx.filePkgs = filePkgs
+	ctx.configs = configs
+	ctx.analyze()
+	return ctx.findings, nil
+}
+
+// mergeTypesInfo adds the entries of src to dst.
+func mergeTypesInfo(dst, src *types.Info) {
+	for k, v := range src.Types {
+		dst.Types[k] = v
+	}
+	for k, v := range src.Instances {
+		dst.Instances[k] = v
+	}
+	for k, v := range src.Defs {
+		dst.Defs[k] = v
+	}
+	for k, v := range src.Uses {
+		dst.Uses[k] = v
+	}
+	for k, v := range src.Implicits {
+		dst.Implicits[k] = v
+	}
+	for k, v := range src.Selections {
+		dst.Selections[k] = v
+	}
+	for k, v := range src.Scopes {
+		dst.Scopes[k] = v
+	}
+	for k, v := range src.FileVersions {
+		dst.FileVersions[k] = v
+	}
+}
+
+// sourceFunctions returns the functions declared in the files of p, including
+// function literals, in source order, like buildssa's SrcFuncs.
+func sourceFunctions(prog *ssa.Program, p *packages.Package) []*ssa.Function {
+	var funcs []*ssa.Function
+	var add func(fn *ssa.Function)
+	add = func(fn *ssa.Function) {
+		funcs = append(funcs, fn)
+		for _, anon := range fn.AnonFuncs {
+			add(anon)
+		}
+	}
+	for _, file := range p.Syntax {
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			obj, ok := p.TypesInfo.Defs[fd.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			if fn := prog.FuncValue(obj); fn != nil {
+				add(fn)
+			}
+		}
+	}
+	return funcs
+}
+
+// packages returns the analyzed packages: ctx.pass.Pkg, or the packages of
+// the program in whole-program mode.
+func (ctx *passContext) packages() []*types.Package {
+	if ctx.pkgs != nil {
+		return ctx.pkgs
+	}
+	return []*types.Package{ctx.pass.Pkg}
+}
+
+// isLocal reports whether pkg is analyzed, as opposed to imported: its types
+// get their guards inferred rather than imported from facts.
+func (ctx *passContext) isLocal(pkg *types.Package) bool {
+	if ctx.localPkgs != nil {
+		return ctx.localPkgs[pkg]
+	}
+	return pkg == ctx.pass.Pkg
+}
+
+// pkgOf returns the analyzed package fn belongs to, the package names are
+// written relative to in fn's findings.
+func (ctx *passContext) pkgOf(fn *ssa.Function) *types.Package {
+	if ctx.localPkgs != nil && fn != nil && fn.Pkg != nil && ctx.localPkgs[fn.Pkg.Pkg] {
+		return fn.Pkg.Pkg
+	}
+	return ctx.pass.Pkg
+}
+
+// pkgAt returns the analyzed package of the file containing pos.
+func (ctx *passContext) pkgAt(pos token.Pos) *types.Package {
+	if pkg := ctx.filePkgs[ctx.pass.Fset.File(pos)]; pkg != nil {
+		return pkg
+	}
+	return ctx.pass.Pkg
+}
+
+// configOf returns the configuration of the analyzed package pkg:
+// ctx.config, or the package's own in whole-program mode.
+func (ctx *passContext) configOf(pkg *types.Package) *config {
+	if cfg := ctx.configs[pkg]; cfg != nil {
+		return cfg
+	}
+	return ctx.config
+}
+
+// checksOf returns the checks selected for pkg.
+func (ctx *passContext) checksOf(pkg *types.Package) *checkSelection {
+	if ctx.configs == nil {
+		return &ctx.checks
+	}
+	sel := ctx.configOf(pkg).effectiveChecks(checks)
+	return &sel
+}
+
+// severitiesOf returns the severity overrides of pkg.
+func (ctx *passContext) severitiesOf(pkg *types.Package) severityMap {
+	if ctx.configs == nil {
+		return ctx.severities
+	}
+	return ctx.configOf(pkg).effectiveSeverities(severities)
+}
+
+// checkEnabled reports whether the check is selected for an analyzed
+// package: its phase runs, and report drops the findings of the others.
+func (ctx *passContext) checkEnabled(id string) bool {
+	for _, pkg := range ctx.packages() {
+		if ctx.checksOf(pkg).enabled(id) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package analyzer
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// recordLockedCallback records that fn invokes its function-typed parameter
+// param while the locks in ls are held. Lock wrappers such as
+//
+//	func (s *S) withLock(fn func()) { s.mu.Lock(); fn(); s.mu.Unlock() }
+//
+// are recognized this way. When the parameter is invoked several times (or a
+// block is re-walked with a smaller state), only the locks held at every
+// invocation are kept.
+func (ctx *passContext) recordLockedCallback(fn *ssa.Function, param *ssa.Parameter, ls *lockState) {
+	idx := -1
+	for i, p := range fn.Params {
+		if p == param {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return
+	}
+
+	held := make(map[mutexFieldKey]bool)
+	for ref, hl := range ls.held {
+		if mfk, ok := lockRefToMutexFieldKey(&ref); ok {
+			held[mfk] = hl.exclusive
+		}
+	}
+
+	facts := ctx.getOrCreateFuncFacts(fn)
+	prev, seen := facts.LockedCallbacks[idx]
+	if !seen {
+		facts.LockedCallbacks[idx] = held
+		return
+	}
+	for mfk, exclusive := range prev {
+		if cur, ok := held[mfk]; !ok || cur != exclusive {
+			delete(prev, mfk)
+		}
+	}
+}
+
+// lockedCallbackParams returns the locks callee holds when invoking each of
+// its function-typed parameters.
+func (ctx *passContext) lockedCallbackParams(callee *ssa.Function) map[int]map[mutexFieldKey]bool {
+	if facts := ctx.calleeLockFacts(callee); facts != nil {
+		return facts.LockedCallbacks
+	}
+	return nil
+}
+
+// callbackEntryState returns the lock state at entry of fn. A closure passed
+// directly to a lock wrapper starts with the wrapper's locks held; they are
+// marked as released by the wrapper so that returning from the closure is not
+// a lock leak.
+func (ctx *passContext) callbackEntryState(fn *ssa.Function) *lockState {
+	ls := newLockState()
+	if fn.Parent() == nil {
+		return ls
+	}
+	for _, cs := range ctx.callSites {
+		if cs.Caller != fn.Parent() {
+			continue
+		}
+		for i, arg := range cs.Args {
+			mc, ok := arg.(*ssa.MakeClosure)
+			if !ok || mc.Fn != fn {
+				continue
+			}
+			held := ctx.lockedCallbackParams(cs.Callee)[i]
+			if len(held) == 0 {
+				continue
+			}
+			for mfk, exclusive := range held {
+				for _, ref := range ctx.callbackLockRefs(fn, mc, cs, mfk) {
+					ls.lock(ref, exclusive, cs.Pos)
+					ls.deferUnlock(ref)
+				}
+			}
+			ctx.seededCallbacks[fn] = true
+			return ls
+		}
+	}
+	return ls
+}
+
+// callbackLockRefs maps a type-scoped lock held by a wrapper to lockRefs in
+// the closure's terms. A package-level mutex maps to its *ssa.Global. A mutex
+// field maps to the closure's free variables of that struct type, preferring
+// the one bound to the wrapper's receiver.
+func (ctx *passContext) callbackLockRefs(fn *ssa.Function, mc *ssa.MakeClosure, cs callSiteRecord, mfk mutexFieldKey) []lockRef {
+	if mfk.Global != nil {
+		g := ctx.ssaGlobal(mfk.Global)
+		if g == nil {
+			return nil
+		}
+		return []lockRef{{kind: globalLock, base: g, fieldIndex: -1}}
+	}
+
+	var all, bound []lockRef
+	for k, fv := range fn.FreeVars {
+		named, _, ok := resolveStructFromBase(fv)
+		if !ok || named != mfk.StructType || k >= len(mc.Bindings) {
+			continue
+		}
+		ref := lockRef{kind: fieldLock, base: fv, fieldIndex: mfk.FieldIndex}
+		all = append(all, ref)
+		if cs.ReceiverValue != nil && canonicalizeBase(mc.Bindings[k]) == canonicalizeBase(cs.ReceiverValue) {
+			bound = append(bound, ref)
+		}
+	}
+	if len(bound) > 0 {
+		return bound
+	}
+	return all
+}
+
+// ssaGlobal returns the *ssa.Global for a package-level variable.
+func (ctx *passContext) ssaGlobal(v *types.Var) *ssa.Global {
+	pkg := ctx.ssaPkg.Prog.Package(v.Pkg())
+	if pkg == nil {
+		return nil
+	}
+	return pkg.Var(v.Name())
+}
+
+// addLockedCallbackCallSites records a call site from the wrapper's caller to
+// each callback passed to a lock wrapper, so that the callback is part of the
+// call graph (requirements, concurrency reachability, double locks). Closures
+// already walked with the wrapper's locks held only inherit the caller's
+// locks; other callbacks (function references, method values) are treated as
+// called with the wrapper's locks held.
+func (ctx *passContext) addLockedCallbackCallSites() {
+	n := len(ctx.callSites)
+	for i := 0; i < n; i++ {
+		cs := ctx.callSites[i]
+		params := ctx.lockedCallbackParams(cs.Callee)
+		if len(params) == 0 {
+			continue
+		}
+		for idx, held := range params {
+			// Parameters invoked without a lock held are plain callbacks.
+			if len(held) == 0 || idx >= len(cs.Args) {
+				continue
+			}
+			target := ctx.resolveCallbackFunc(cs.Args[idx])
+			if target == nil {
+				continue
+			}
+			synth := callSiteRecord{
+				Caller:           cs.Caller,
+				Callee:           target,
+				Pos:              cs.Pos,
+				HeldByStructType: make(map[*types.Named][]heldMutexRef),
+				HeldGlobals:      append([]heldGlobalMutex(nil), cs.HeldGlobals...),
+			}
+			for st, refs := range cs.HeldByStructType {
+				synth.HeldByStructType[st] = append([]heldMutexRef(nil), refs...)
+			}
+			if !ctx.seededCallbacks[target] {
+				for mfk, exclusive := range held {
+					if mfk.Global != nil {
+						synth.HeldGlobals = append(synth.HeldGlobals, heldGlobalMutex{Mutex: mfk.Global, Exclusive: exclusive})
+						continue
+					}
+					synth.HeldByStructType[mfk.StructType] = append(synth.HeldByStructType[mfk.StructType],
+						heldMutexRef{FieldIndex: mfk.FieldIndex, Exclusive: exclusive})
+				}
+			}
+			ctx.callSites = append(ctx.callSites, synth)
+		}
+	}
+}
+
+// closureDepth returns the nesting depth of an anonymous function (0 for
+// package-level functions and methods).
+func closureDepth(fn *ssa.Function) int {
+	depth := 0
+	for p := fn.Parent(); p != nil; p = p.Parent() {
+		depth++
+	}
+	return depth
+}